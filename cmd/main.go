@@ -6,9 +6,14 @@ import (
 	"os"
 	"os/signal"
 	"tanysu-bot/config"
+	"tanysu-bot/internal/avatar"
+	"tanysu-bot/internal/dispatcher"
 	"tanysu-bot/internal/handler"
+	"tanysu-bot/internal/keyboard"
 	"tanysu-bot/internal/repository"
+	"tanysu-bot/internal/sender"
 	"tanysu-bot/traits/database"
+	"time"
 
 	"github.com/go-telegram/bot"
 )
@@ -30,14 +35,41 @@ func main() {
 	userRepository := repository.NewRepository(dbConn)
 	chatRedisState := repository.NewRedisClient(redisClient)
 
-	handler := handler.NewHandler(chatRedisState, userRepository, cfg)
+	callbacks := keyboard.NewCallbackRegistry(keyboard.NewRedisCallbackStore(redisClient))
+
+	outbound := sender.New(sender.Limits{
+		GlobalPerSecond: cfg.SendGlobalPerSecond,
+		ChatPerSecond:   cfg.SendChatPerSecond,
+		GroupPerMinute:  cfg.SendGroupPerMinute,
+		Burst:           cfg.SendBurst,
+		QueueSize:       cfg.SendQueueSize,
+		MaxRetries:      cfg.SendMaxRetries,
+	})
+
+	avatars := avatar.NewLocalStore(cfg.AvatarDir, cfg.AvatarThumbSize)
+
+	handler := handler.NewHandler(chatRedisState, userRepository, callbacks, outbound, avatars, cfg)
+
+	// Обновления одного чата обрабатываются по порядку, разных чатов — параллельно.
+	// Для этого библиотека должна вызывать middleware последовательно.
+	updates := dispatcher.New(handler.DispatchKey, cfg.DispatcherQueueSize)
 
 	opts := []bot.Option{
+		bot.WithNotAsyncHandlers(),
+		bot.WithMiddlewares(updates.Middleware),
 		bot.WithCallbackQueryDataHandler("chat", bot.MatchTypePrefix, handler.ChatButtonHandler),
 		bot.WithCallbackQueryDataHandler("select_", bot.MatchTypePrefix, handler.InlineHandler),
 		bot.WithCallbackQueryDataHandler("send_geo", bot.MatchTypePrefix, handler.InlineHandler),
 		bot.WithCallbackQueryDataHandler("exit", bot.MatchTypePrefix, handler.CallbackHandlerExit),
 		bot.WithCallbackQueryDataHandler("delete_", bot.MatchTypePrefix, handler.DeleteMessageHandler),
+		bot.WithCallbackQueryDataHandler("burn", bot.MatchTypeExact, handler.BurnChatHandler),
+		bot.WithCallbackQueryDataHandler("ephemeral_", bot.MatchTypePrefix, handler.EphemeralAnswerHandler),
+		bot.WithCallbackQueryDataHandler("retry_", bot.MatchTypePrefix, handler.RetryDeliveryHandler),
+		bot.WithCallbackQueryDataHandler("edit", bot.MatchTypeExact, handler.EditHandler),
+		bot.WithCallbackQueryDataHandler("edit_", bot.MatchTypePrefix, handler.EditFieldHandler),
+		bot.WithCallbackQueryDataHandler("purge_", bot.MatchTypePrefix, handler.DeleteMeAnswerHandler),
+		bot.WithCallbackQueryDataHandler("city:", bot.MatchTypePrefix, handler.CityPickerHandler),
+		bot.WithCallbackQueryDataHandler("loc_", bot.MatchTypePrefix, handler.LocationConfirmHandler),
 	}
 
 	// Replace with your bot token
@@ -49,6 +81,21 @@ func main() {
 		return
 	}
 
+	// Команды регистрируем раньше общего хендлера сообщений, иначе он перехватит их первым.
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/typing", bot.MatchTypeExact, handler.TypingToggleHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/echo", bot.MatchTypeExact, handler.EchoToggleHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/ephemeral", bot.MatchTypePrefix, handler.EphemeralHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/profile", bot.MatchTypeExact, handler.ProfileHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/edit", bot.MatchTypeExact, handler.EditHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/delete_me", bot.MatchTypeExact, handler.DeleteMeHandler)
+	b.RegisterHandler(bot.HandlerTypeMessageText, "/mydata", bot.MatchTypeExact, handler.MyDataHandler)
+
+	// Обновления live-локации приходят как edited_message.
+	b.RegisterHandlerMatchFunc(handler.IsLiveLocationUpdate, handler.LiveLocationHandler)
+
+	// Голоса в опросах, отправленных ботом, приходят как poll_answer и poll.
+	b.RegisterHandlerMatchFunc(handler.IsPollUpdate, handler.PollUpdateHandler)
+
 	// 1) Регистрируем хендлер для обычных сообщений (пересылка между собеседниками)
 	b.RegisterHandler(
 		bot.HandlerTypeMessageText,
@@ -59,6 +106,18 @@ func main() {
 
 	b.RegisterHandler(bot.HandlerTypeMessageText, "/hello", bot.MatchTypeExact, handler.HelloHandler)
 
+	// Город по геолокации пользователей, зарегистрированных до появления справочника городов.
+	handler.BackfillCities()
+	// Координаты, сохранённые до огрубления, приводим к сетке GeoPrecisionKm.
+	handler.FuzzStoredLocations()
+
+	// Планировщик удаления самоудаляющихся сообщений.
+	go handler.RunDeletionScheduler(ctx, b)
+	// Повторная доставка сообщений, не дошедших до собеседника из-за временных сбоев.
+	go handler.RunOutbox(ctx, b)
+	go updates.LogStats(ctx, time.Minute)
+	go handler.LogCityStats(ctx, time.Hour)
+
 	fmt.Println("Bot is running...")
 	b.Start(ctx)
 }
@@ -9,6 +9,57 @@ type Config struct {
 	ChannelID     int64  `json:"channelID"`      // Идентификатор канала
 	ChannelName   string `json:"channelName"`
 
+	// TypingPulseMs — сколько миллисекунд собеседник видит "печатает…" перед сообщением,
+	// если отправитель включил эту опцию командой /typing.
+	TypingPulseMs int `json:"typing_pulse_ms"`
+
+	// BurnChannelCopies разрешает "🔥 Өртеу" удалять и копии сообщений в канале.
+	BurnChannelCopies bool `json:"burn_channel_copies"`
+
+	// DiceReroll: true — собеседнику бросается свой кубик, false — ему показывается результат отправителя.
+	DiceReroll bool `json:"dice_reroll"`
+
+	// DispatcherQueueSize — сколько необработанных обновлений может ждать в очереди одного чата.
+	DispatcherQueueSize int `json:"dispatcher_queue_size"`
+
+	// Лимиты исходящих сообщений: на весь бот, в личный чат и в группу/канал (в том числе канал аудита).
+	SendGlobalPerSecond float64 `json:"send_global_per_second"`
+	SendChatPerSecond   float64 `json:"send_chat_per_second"`
+	SendGroupPerMinute  float64 `json:"send_group_per_minute"`
+	SendBurst           int     `json:"send_burst"`
+	// SendQueueSize — сколько сообщений может ждать отправки в один чат.
+	SendQueueSize int `json:"send_queue_size"`
+	// SendMaxRetries — сколько раз повторять отправку после ответа 429.
+	SendMaxRetries int `json:"send_max_retries"`
+
+	// OutboxMaxAttempts — после скольких неудачных попыток сообщение из очереди доставки
+	// бросается, а отправителю сообщается, что оно не доставлено.
+	OutboxMaxAttempts int `json:"outbox_max_attempts"`
+
+	// AvatarDir — директория, где хранятся аватары и их миниатюры.
+	AvatarDir string `json:"avatar_dir"`
+	// AvatarThumbSize — размер стороны квадрата, в который вписывается миниатюра аватара.
+	AvatarThumbSize int `json:"avatar_thumb_size"`
+
+	// DataExportCooldownHours — как часто пользователь может выгружать свои данные командой /mydata.
+	DataExportCooldownHours int `json:"data_export_cooldown_hours"`
+
+	// GeoPrecisionKm — до какой сетки огрубляются сохранённые координаты пользователей, в километрах.
+	GeoPrecisionKm float64 `json:"geo_precision_km"`
+	// ConfirmExactLocation — спрашивать отправителя, пересылать собеседнику точную геолокацию или примерную.
+	ConfirmExactLocation bool `json:"confirm_exact_location"`
+
+	// MinAge и MaxAge — допустимый возраст пользователя; младше MinAge зарегистрироваться нельзя.
+	MinAge int `json:"min_age"`
+	MaxAge int `json:"max_age"`
+	// AdultAge — возраст совершеннолетия: несовершеннолетних никогда не соединяем со взрослыми.
+	AdultAge int `json:"adult_age"`
+	// MinorsAllowMedia — могут ли несовершеннолетние пересылать в чате фото, видео, голосовые и файлы.
+	MinorsAllowMedia bool `json:"minors_allow_media"`
+	// MinorsAllowContacts — могут ли несовершеннолетние пересылать контакты, геолокацию,
+	// номера телефонов и ссылки на профили в соцсетях.
+	MinorsAllowContacts bool `json:"minors_allow_contacts"`
+
 	// Параметры для SQLite (используем DBName как путь к файлу базы)
 	DBName string `json:"db_name"`
 }
@@ -23,6 +74,35 @@ func NewConfig() (*Config, error) {
 		ChannelID:     2403228914,
 		ChannelName:   "@jaiAngmeAitamyz",
 		DBName:        "tanysu.db", // Имя файла базы данных SQLite
+
+		TypingPulseMs:     800,
+		BurnChannelCopies: false,
+		DiceReroll:        false,
+
+		DispatcherQueueSize: 50,
+
+		SendGlobalPerSecond: 30,
+		SendChatPerSecond:   1,
+		SendGroupPerMinute:  20,
+		SendBurst:           5,
+		SendQueueSize:       200,
+		SendMaxRetries:      3,
+
+		OutboxMaxAttempts: 8,
+
+		AvatarDir:       "./ava",
+		AvatarThumbSize: 160,
+
+		DataExportCooldownHours: 24,
+
+		GeoPrecisionKm:       1,
+		ConfirmExactLocation: true,
+
+		MinAge:              14,
+		MaxAge:              99,
+		AdultAge:            18,
+		MinorsAllowMedia:    false,
+		MinorsAllowContacts: false,
 	}
 	return cfg, nil
 }
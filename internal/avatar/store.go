@@ -0,0 +1,101 @@
+package avatar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store хранит аватары пользователей вместе с миниатюрами.
+type Store interface {
+	// Save сохраняет аватар и миниатюру, заменяя прежние, и возвращает путь к аватару.
+	Save(ctx context.Context, userID int64, data []byte) (string, error)
+	// Load возвращает сохранённый аватар; если его нет, ошибка оборачивает os.ErrNotExist.
+	Load(ctx context.Context, userID int64) ([]byte, error)
+	// Delete удаляет аватар и миниатюру; отсутствие файлов ошибкой не считается.
+	Delete(ctx context.Context, userID int64) error
+}
+
+// LocalStore хранит аватары в директории на диске: <dir>/<id>.jpg и <dir>/<id>_thumb.jpg.
+type LocalStore struct {
+	dir       string
+	thumbSize int
+}
+
+// NewLocalStore создаёт хранилище в dir; миниатюра вписывается в квадрат thumbSize×thumbSize.
+func NewLocalStore(dir string, thumbSize int) *LocalStore {
+	return &LocalStore{dir: dir, thumbSize: thumbSize}
+}
+
+func (s *LocalStore) avatarPath(userID int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d.jpg", userID))
+}
+
+func (s *LocalStore) thumbPath(userID int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d_thumb.jpg", userID))
+}
+
+func (s *LocalStore) Save(ctx context.Context, userID int64, data []byte) (string, error) {
+	// Миниатюра заодно проверяет, что это действительно картинка.
+	thumb, err := Thumbnail(data, s.thumbSize)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create avatar dir: %w", err)
+	}
+
+	path := s.avatarPath(userID)
+	if err := writeFileAtomic(path, data); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(s.thumbPath(userID), thumb); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (s *LocalStore) Load(ctx context.Context, userID int64) ([]byte, error) {
+	data, err := os.ReadFile(s.avatarPath(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	return data, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, userID int64) error {
+	var errs []error
+	for _, path := range []string{s.avatarPath(userID), s.thumbPath(userID)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove avatar: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeFileAtomic пишет во временный файл и переименовывает его, чтобы при замене
+// аватара никто не увидел наполовину записанный файл.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".avatar-*")
+	if err != nil {
+		return fmt.Errorf("failed to create avatar file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write avatar file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write avatar file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write avatar file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace avatar file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testImage(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestThumbnail(t *testing.T) {
+	thumb, err := Thumbnail(testImage(t, 640, 320, color.White), 160)
+	assert.NoError(t, err)
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, 160, cfg.Width)
+	assert.Equal(t, 80, cfg.Height)
+
+	// Маленькие картинки не увеличиваются.
+	thumb, err = Thumbnail(testImage(t, 50, 100, color.White), 160)
+	assert.NoError(t, err)
+	cfg, err = jpeg.DecodeConfig(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, 50, cfg.Width)
+	assert.Equal(t, 100, cfg.Height)
+
+	_, err = Thumbnail([]byte("not an image"), 160)
+	assert.Error(t, err)
+}
+
+func TestLocalStore_SaveReplaceDelete(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ava")
+	store := NewLocalStore(dir, 160)
+	ctx := context.Background()
+
+	first := testImage(t, 400, 400, color.White)
+	path, err := store.Save(ctx, 123, first)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "123.jpg"), path)
+	assert.FileExists(t, filepath.Join(dir, "123_thumb.jpg"))
+
+	second := testImage(t, 300, 300, color.Black)
+	_, err = store.Save(ctx, 123, second)
+	assert.NoError(t, err)
+	data, err := store.Load(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, second, data)
+
+	_, err = store.Save(ctx, 123, []byte("broken"))
+	assert.Error(t, err)
+	data, _ = os.ReadFile(path)
+	assert.Equal(t, second, data, "неудачная замена не должна портить прежний аватар")
+
+	assert.NoError(t, store.Delete(ctx, 123))
+	assert.NoFileExists(t, path)
+	_, err = store.Load(ctx, 123)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.NoFileExists(t, filepath.Join(dir, "123_thumb.jpg"))
+	assert.NoError(t, store.Delete(ctx, 123))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "временные файлы не должны оставаться")
+}
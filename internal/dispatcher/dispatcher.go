@@ -0,0 +1,177 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// KeyFunc возвращает ключ очереди для обновления (пользователь или сессия).
+// Пустой ключ — обновление не привязано к чату и обрабатывается сразу.
+type KeyFunc func(ctx context.Context, update *models.Update) string
+
+// Dispatcher обрабатывает обновления с одним ключом строго по порядку,
+// а обновления с разными ключами — параллельно.
+type Dispatcher struct {
+	key      KeyFunc
+	maxQueue int
+
+	mu      sync.Mutex
+	queues  map[string][]job
+	dropped atomic.Int64
+}
+
+type job struct {
+	ctx    context.Context
+	b      *bot.Bot
+	update *models.Update
+	next   bot.HandlerFunc
+}
+
+// Stats — снимок состояния очередей.
+type Stats struct {
+	Queues  int   // ключей с необработанными обновлениями
+	Pending int   // обновлений в очередях, включая обрабатываемые
+	Longest int   // длина самой длинной очереди
+	Dropped int64 // обновлений, отброшенных из-за переполнения
+}
+
+// New создаёт диспетчер; maxQueue — максимальная длина очереди одного ключа.
+func New(key KeyFunc, maxQueue int) *Dispatcher {
+	if maxQueue < 1 {
+		maxQueue = 1
+	}
+	return &Dispatcher{
+		key:      key,
+		maxQueue: maxQueue,
+		queues:   make(map[string][]job),
+	}
+}
+
+// Middleware ставит обновление в очередь его ключа. Порядок сохраняется, только если бот
+// вызывает middleware последовательно (bot.WithNotAsyncHandlers), поэтому сам middleware не блокируется.
+func (d *Dispatcher) Middleware(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		key := d.key(ctx, update)
+		if key == "" {
+			go next(ctx, b, update)
+			return
+		}
+		if !d.enqueue(key, job{ctx: ctx, b: b, update: update, next: next}) {
+			d.dropped.Add(1)
+			fmt.Printf("[DISPATCHER] Очередь %s переполнена, обновление %d отброшено\n", key, update.ID)
+		}
+	}
+}
+
+// enqueue добавляет задачу и, если очередь была пуста, запускает для неё обработчик.
+func (d *Dispatcher) enqueue(key string, j job) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	queue, running := d.queues[key]
+	if len(queue) >= d.maxQueue {
+		return false
+	}
+	d.queues[key] = append(queue, j)
+	if !running {
+		go d.drain(key)
+	}
+	return true
+}
+
+// drain обрабатывает очередь ключа, пока она не опустеет; пустая очередь удаляется.
+// Текущая задача остаётся в очереди до завершения, чтобы новая не запустила второй обработчик.
+func (d *Dispatcher) drain(key string) {
+	for {
+		d.mu.Lock()
+		j := d.queues[key][0]
+		d.mu.Unlock()
+
+		d.run(key, j)
+
+		d.mu.Lock()
+		queue := d.queues[key][1:]
+		if len(queue) == 0 {
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		d.queues[key] = queue
+		d.mu.Unlock()
+	}
+}
+
+// run вызывает хендлер; паника в нём не должна останавливать очередь.
+// В контекст хендлера кладётся его очередь, чтобы он мог узнать о следующих обновлениях.
+func (d *Dispatcher) run(key string, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("Ошибка при обработке обновления:", r)
+		}
+	}()
+	ctx := context.WithValue(j.ctx, queueContextKey{}, queueRef{d: d, key: key})
+	j.next(ctx, j.b, j.update)
+}
+
+// queueContextKey — ключ контекста, под которым хендлер находит свою очередь.
+type queueContextKey struct{}
+
+type queueRef struct {
+	d   *Dispatcher
+	key string
+}
+
+// Pending возвращает, сколько обновлений ждут в очереди после обрабатываемого.
+// Вне диспетчера всегда 0.
+func Pending(ctx context.Context) int {
+	ref, ok := ctx.Value(queueContextKey{}).(queueRef)
+	if !ok {
+		return 0
+	}
+	return max(ref.d.Len(ref.key)-1, 0)
+}
+
+// Len возвращает длину очереди ключа.
+func (d *Dispatcher) Len(key string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.queues[key])
+}
+
+// Stats возвращает текущее состояние очередей.
+func (d *Dispatcher) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := Stats{Queues: len(d.queues), Dropped: d.dropped.Load()}
+	for _, queue := range d.queues {
+		stats.Pending += len(queue)
+		stats.Longest = max(stats.Longest, len(queue))
+	}
+	return stats
+}
+
+// LogStats периодически выводит состояние очередей, если в них что-то есть.
+func (d *Dispatcher) LogStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if stats := d.Stats(); stats.Pending > 0 || stats.Dropped > 0 {
+				fmt.Printf("[DISPATCHER] Queues=%d | Pending=%d | Longest=%d | Dropped=%d\n",
+					stats.Queues, stats.Pending, stats.Longest, stats.Dropped)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// keyByChat раскладывает обновления по ChatID сообщения.
+func keyByChat(ctx context.Context, update *models.Update) string {
+	return fmt.Sprintf("chat:%d", update.Message.Chat.ID)
+}
+
+func newUpdate(id, chatID int64) *models.Update {
+	return &models.Update{ID: id, Message: &models.Message{Chat: models.Chat{ID: chatID}}}
+}
+
+func TestDispatcher_OrderPerKey(t *testing.T) {
+	d := New(keyByChat, 100)
+	ctx := context.Background()
+
+	var (
+		mu   sync.Mutex
+		seen []int64
+		wg   sync.WaitGroup
+	)
+	handler := d.Middleware(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		// Первые обновления обрабатываются дольше: без очереди они бы отстали.
+		time.Sleep(time.Duration(10-update.ID) * time.Millisecond)
+		mu.Lock()
+		seen = append(seen, update.ID)
+		mu.Unlock()
+		wg.Done()
+	})
+
+	for i := int64(0); i < 10; i++ {
+		wg.Add(1)
+		handler(ctx, nil, newUpdate(i, 1))
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, seen)
+	assert.Equal(t, 0, d.Len("chat:1"))
+}
+
+func TestDispatcher_ParallelKeys(t *testing.T) {
+	d := New(keyByChat, 100)
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	done := make(chan int64, 2)
+	handler := d.Middleware(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		if update.Message.Chat.ID == 1 {
+			<-release
+		}
+		done <- update.Message.Chat.ID
+	})
+
+	handler(ctx, nil, newUpdate(1, 1))
+	handler(ctx, nil, newUpdate(2, 2))
+
+	// Чат 2 не ждёт, пока освободится чат 1.
+	select {
+	case chatID := <-done:
+		assert.Equal(t, int64(2), chatID)
+	case <-time.After(time.Second):
+		t.Fatal("update for chat 2 was blocked by chat 1")
+	}
+	close(release)
+	assert.Equal(t, int64(1), <-done)
+}
+
+func TestDispatcher_QueueCap(t *testing.T) {
+	d := New(keyByChat, 2)
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	handler := d.Middleware(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		<-release
+		wg.Done()
+	})
+
+	wg.Add(2)
+	for i := int64(0); i < 5; i++ {
+		handler(ctx, nil, newUpdate(i, 1))
+	}
+
+	stats := d.Stats()
+	assert.Equal(t, 1, stats.Queues)
+	assert.Equal(t, 2, stats.Pending)
+	assert.Equal(t, int64(3), stats.Dropped)
+
+	close(release)
+	wg.Wait()
+}
+
+func TestDispatcher_Pending(t *testing.T) {
+	d := New(keyByChat, 100)
+	ctx := context.Background()
+	assert.Equal(t, 0, Pending(ctx))
+
+	release := make(chan struct{})
+	pending := make(chan int, 3)
+	handler := d.Middleware(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		if update.ID == 1 {
+			<-release
+		}
+		pending <- Pending(ctx)
+	})
+
+	handler(ctx, nil, newUpdate(1, 1))
+	handler(ctx, nil, newUpdate(2, 1))
+	handler(ctx, nil, newUpdate(3, 1))
+	close(release)
+
+	// Первое обновление видит два следующих, последнее — ни одного.
+	assert.Equal(t, 2, <-pending)
+	assert.Equal(t, 1, <-pending)
+	assert.Equal(t, 0, <-pending)
+}
@@ -0,0 +1,175 @@
+// Package geo определяет ближайший город по координатам без обращений к сети.
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm — средний радиус Земли.
+const earthRadiusKm = 6371.0
+
+// MaxCityDistanceKm — дальше этого расстояния от ближайшего города точка считается "вне городов".
+const MaxCityDistanceKm = 100.0
+
+//go:embed cities.csv
+var citiesCSV string
+
+// City — город из встроенного справочника: Казахстан и соседние страны.
+type City struct {
+	Name    string
+	Region  string
+	Country string
+	Lat     float64
+	Lon     float64
+}
+
+// homeCountry — страна, города которой в выборе группируются по областям, а не по странам.
+const homeCountry = "Қазақстан"
+
+// Area возвращает группу, в которой город показывается при выборе вручную:
+// область для Казахстана и страну для соседей.
+func (c City) Area() string {
+	if c.Country == homeCountry {
+		return c.Region
+	}
+	return c.Country
+}
+
+// cities разбирается один раз при старте; справочник встроен в бинарник, поэтому ошибка в нём — ошибка сборки.
+var cities = mustParseCities(citiesCSV)
+
+func mustParseCities(data string) []City {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("geo: failed to parse cities: %v", err))
+	}
+	result := make([]City, 0, len(records))
+	for i, rec := range records[1:] {
+		if len(rec) != 5 {
+			panic(fmt.Sprintf("geo: bad cities row %d", i+2))
+		}
+		lat, errLat := strconv.ParseFloat(rec[3], 64)
+		lon, errLon := strconv.ParseFloat(rec[4], 64)
+		if errLat != nil || errLon != nil {
+			panic(fmt.Sprintf("geo: bad coordinates in cities row %d", i+2))
+		}
+		result = append(result, City{Name: rec[0], Region: rec[1], Country: rec[2], Lat: lat, Lon: lon})
+	}
+	return result
+}
+
+// Cities возвращает копию справочника городов.
+func Cities() []City {
+	return append([]City(nil), cities...)
+}
+
+// Areas возвращает группы городов в порядке справочника.
+func Areas() []string {
+	var areas []string
+	seen := make(map[string]bool)
+	for _, city := range cities {
+		if area := city.Area(); !seen[area] {
+			seen[area] = true
+			areas = append(areas, area)
+		}
+	}
+	return areas
+}
+
+// CitiesIn возвращает города группы area.
+func CitiesIn(area string) []City {
+	var result []City
+	for _, city := range cities {
+		if city.Area() == area {
+			result = append(result, city)
+		}
+	}
+	return result
+}
+
+// FindCity ищет город справочника по названию.
+func FindCity(name string) (City, bool) {
+	for _, city := range cities {
+		if city.Name == name {
+			return city, true
+		}
+	}
+	return City{}, false
+}
+
+// Distance возвращает расстояние по дуге большого круга в километрах.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Nearest возвращает ближайший к точке город и расстояние до него в километрах.
+func Nearest(lat, lon float64) (City, float64) {
+	var best City
+	bestDist := math.Inf(1)
+	for _, city := range cities {
+		if d := Distance(lat, lon, city.Lat, city.Lon); d < bestDist {
+			best, bestDist = city, d
+		}
+	}
+	return best, bestDist
+}
+
+// Lookup возвращает город, в котором (или рядом с которым) находится точка.
+// false — до ближайшего города дальше MaxCityDistanceKm.
+func Lookup(lat, lon float64) (City, bool) {
+	city, dist := Nearest(lat, lon)
+	if dist > MaxCityDistanceKm {
+		return City{}, false
+	}
+	return city, true
+}
+
+// ParsePoint разбирает координаты в формате user_geo: "широта,долгота".
+func ParsePoint(s string) (lat, lon float64, ok bool) {
+	latStr, lonStr, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lon, errLon := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// kmPerDegree — длина одного градуса широты (и градуса долготы на экваторе).
+const kmPerDegree = earthRadiusKm * math.Pi / 180
+
+// Fuzz огрубляет точку до сетки с шагом около precisionKm: все точки одной ячейки превращаются
+// в её центр, поэтому по результату нельзя восстановить исходные координаты точнее ячейки.
+// Повторный вызов возвращает ту же точку. precisionKm <= 0 оставляет точку без изменений.
+func Fuzz(lat, lon, precisionKm float64) (float64, float64) {
+	if precisionKm <= 0 {
+		return lat, lon
+	}
+	latStep := precisionKm / kmPerDegree
+	fLat := math.Max(-90, math.Min(90, (math.Floor(lat/latStep)+0.5)*latStep))
+	// Шаг по долготе считаем по широте центра ячейки, чтобы он был одинаковым для всей ячейки.
+	lonStep := precisionKm / (kmPerDegree * math.Max(math.Cos(fLat*math.Pi/180), 0.01))
+	fLon := (math.Floor(lon/lonStep) + 0.5) * lonStep
+	if fLon > 180 {
+		fLon -= 360
+	}
+	return fLat, fLon
+}
+
+// FormatPoint записывает координаты в формате user_geo.
+func FormatPoint(lat, lon float64) string {
+	return fmt.Sprintf("%.5f,%.5f", lat, lon)
+}
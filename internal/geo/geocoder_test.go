@@ -0,0 +1,95 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCities_Parsed(t *testing.T) {
+	all := Cities()
+	assert.Greater(t, len(all), 50)
+	names := make(map[string]bool)
+	for _, city := range all {
+		assert.NotEmpty(t, city.Name)
+		assert.NotEmpty(t, city.Country)
+		assert.False(t, names[city.Name], "город %s повторяется", city.Name)
+		names[city.Name] = true
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// Алматы — Астана около 970 км.
+	d := Distance(43.2389, 76.8897, 51.1694, 71.4491)
+	assert.InDelta(t, 970, d, 15)
+	assert.Equal(t, 0.0, Distance(43.2389, 76.8897, 43.2389, 76.8897))
+}
+
+func TestLookup(t *testing.T) {
+	// Точка в центре Алматы.
+	city, ok := Lookup(43.25667, 76.92861)
+	assert.True(t, ok)
+	assert.Equal(t, "Алматы", city.Name)
+	assert.Equal(t, "Қазақстан", city.Country)
+
+	city, ok = Lookup(41.31, 69.28)
+	assert.True(t, ok)
+	assert.Equal(t, "Ташкент", city.Name)
+
+	// Середина Бетпақдалы — далеко от всех городов.
+	_, ok = Lookup(45.5, 70.5)
+	assert.False(t, ok)
+}
+
+func TestParsePoint(t *testing.T) {
+	lat, lon, ok := ParsePoint("43.23890,76.88970")
+	assert.True(t, ok)
+	assert.Equal(t, 43.2389, lat)
+	assert.Equal(t, 76.8897, lon)
+	assert.Equal(t, "43.23890,76.88970", FormatPoint(lat, lon))
+
+	for _, s := range []string{"", "43.2", "abc,def", "95,10"} {
+		_, _, ok = ParsePoint(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestAreas(t *testing.T) {
+	areas := Areas()
+	assert.Contains(t, areas, "Алматы облысы")
+	assert.Contains(t, areas, "Өзбекстан")
+	assert.NotContains(t, areas, "Қазақстан")
+
+	for _, area := range areas {
+		cities := CitiesIn(area)
+		assert.NotEmpty(t, cities, area)
+		for _, city := range cities {
+			found, ok := FindCity(city.Name)
+			assert.True(t, ok)
+			assert.Equal(t, area, found.Area())
+			// Названия должны помещаться в callback data (64 байта).
+			assert.LessOrEqual(t, len(area)+len("city:a:"), 64, area)
+		}
+	}
+
+	_, ok := FindCity("Атлантида")
+	assert.False(t, ok)
+}
+
+func TestFuzz(t *testing.T) {
+	lat, lon := 43.25667, 76.92861
+	fLat, fLon := Fuzz(lat, lon, 1)
+	// Точка сдвигается не дальше половины диагонали ячейки.
+	assert.LessOrEqual(t, Distance(lat, lon, fLat, fLon), 0.75)
+	assert.NotEqual(t, FormatPoint(lat, lon), FormatPoint(fLat, fLon))
+
+	// Соседние точки внутри одной ячейки неразличимы, повторное огрубление ничего не меняет.
+	nLat, nLon := Fuzz(lat+0.0001, lon+0.0001, 1)
+	assert.Equal(t, FormatPoint(fLat, fLon), FormatPoint(nLat, nLon))
+	rLat, rLon := Fuzz(fLat, fLon, 1)
+	assert.Equal(t, FormatPoint(fLat, fLon), FormatPoint(rLat, rLon))
+
+	zLat, zLon := Fuzz(lat, lon, 0)
+	assert.Equal(t, lat, zLat)
+	assert.Equal(t, lon, zLon)
+}
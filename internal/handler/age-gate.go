@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"tanysu-bot/config"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Ответы отправителю, если сообщение несовершеннолетнего не пропущено в чат.
+const (
+	minorMediaText   = "Кәмелетке толмағандар чатта фото, видео, дауыстық хабарлама мен файл жібере алмайды."
+	minorContactText = "Кәмелетке толмағандар чатта байланыс деректерін жібере алмайды: телефон, геолокация, профиль сілтемесі."
+)
+
+// phonePattern выделяет последовательности цифр с разделителями, похожие на номер телефона,
+// который Telegram не распознал как phone_number, например "8 777 123 45 67". Точка не считается
+// разделителем, чтобы даты вида "12.05.2009" не превращались в одну длинную последовательность.
+var phonePattern = regexp.MustCompile(`\+?\d[\d\s\-()]*\d`)
+
+// Столько цифр бывает в телефонном номере; короче — даты, цены и прочие числа.
+const (
+	phoneMinDigits = 10
+	phoneMaxDigits = 15
+)
+
+// hasPhoneNumber ищет в тексте номер телефона: 10–15 цифр после удаления разделителей.
+func hasPhoneNumber(text string) bool {
+	for _, candidate := range phonePattern.FindAllString(text, -1) {
+		digits := 0
+		for _, r := range candidate {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if digits >= phoneMinDigits && digits <= phoneMaxDigits {
+			return true
+		}
+	}
+	return false
+}
+
+// contactLinks — ссылки, по которым собеседника можно найти вне бота.
+var contactLinks = []string{"t.me/", "telegram.me/", "wa.me/", "instagram.com/", "vk.com/", "tiktok.com/@"}
+
+// ageHint проверяет возраст по настройкам; пустая строка — возраст подходит.
+func ageHint(cfg *config.Config, age int) string {
+	switch {
+	case age < cfg.MinAge:
+		return fmt.Sprintf("Кешіріңіз, бот %d жастан бастап қолжетімді.", cfg.MinAge)
+	case age > cfg.MaxAge:
+		return fmt.Sprintf("Жасыңызды дұрыс көрсетіңіз: %d-%d.", cfg.MinAge, cfg.MaxAge)
+	}
+	return ""
+}
+
+// isMinor сообщает, что пользователь не достиг AdultAge.
+func isMinor(cfg *config.Config, age int) bool {
+	return age < cfg.AdultAge
+}
+
+// canMatch разрешает чат, только если возраст обоих допустим и оба либо взрослые, либо несовершеннолетние.
+func canMatch(cfg *config.Config, a, b int) bool {
+	if ageHint(cfg, a) != "" || ageHint(cfg, b) != "" {
+		return false
+	}
+	return isMinor(cfg, a) == isMinor(cfg, b)
+}
+
+// isMedia сообщает, что сообщение содержит медиа или файл.
+func isMedia(msg *models.Message) bool {
+	return len(msg.Photo) > 0 || msg.Video != nil || msg.Animation != nil || msg.VideoNote != nil ||
+		msg.Voice != nil || msg.Audio != nil || msg.Document != nil || msg.PaidMedia != nil
+}
+
+// hasContactInfo сообщает, что сообщение раскрывает, как связаться с отправителем вне бота.
+func hasContactInfo(msg *models.Message) bool {
+	if msg.Contact != nil || msg.Location != nil || msg.Venue != nil {
+		return true
+	}
+	for _, entity := range append(msg.Entities[:len(msg.Entities):len(msg.Entities)], msg.CaptionEntities...) {
+		switch entity.Type {
+		case models.MessageEntityTypeMention, models.MessageEntityTypeTextMention,
+			models.MessageEntityTypePhoneNumber, models.MessageEntityTypeEmail:
+			return true
+		}
+	}
+	text := strings.ToLower(msg.Text + "\n" + msg.Caption)
+	for _, link := range contactLinks {
+		if strings.Contains(text, link) {
+			return true
+		}
+	}
+	return hasPhoneNumber(text)
+}
+
+// minorRestriction возвращает причину, по которой сообщение нельзя переслать в чат с участием
+// несовершеннолетнего, или пустую строку.
+func minorRestriction(cfg *config.Config, msg *models.Message) string {
+	if !cfg.MinorsAllowContacts && hasContactInfo(msg) {
+		return minorContactText
+	}
+	if !cfg.MinorsAllowMedia && isMedia(msg) {
+		return minorMediaText
+	}
+	return ""
+}
+
+// checkMinorChat не пропускает сообщение, если в чате есть несовершеннолетний и оно нарушает ограничения.
+// Возвращает false, если сообщение отклонено и отправителю уже ответили.
+func (h *Handler) checkMinorChat(ctx context.Context, b *bot.Bot, msg *models.Message, partnerID int64) bool {
+	minor := false
+	for _, id := range []int64{msg.From.ID, partnerID} {
+		user, err := h.userRepo.GetUser(id)
+		if err != nil {
+			// Возраст неизвестен — действуем так, как если бы в чате был несовершеннолетний.
+			fmt.Println("Ошибка получения пользователя:", err)
+			minor = true
+			continue
+		}
+		minor = minor || isMinor(h.config, user.UserAge)
+	}
+	if !minor {
+		return true
+	}
+	reason := minorRestriction(h.config, msg)
+	if reason == "" {
+		return true
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		Text:            "⚠️ Хабарлама жіберілмеді. " + reason,
+		ReplyParameters: &models.ReplyParameters{MessageID: msg.ID, AllowSendingWithoutReply: true},
+		ProtectContent:  true,
+	})
+	return false
+}
+
+// ageMatch загружает обоих пользователей и проверяет, можно ли их соединить.
+func (h *Handler) ageMatch(userID, partnerID int64) bool {
+	user, err := h.userRepo.GetUser(userID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return false
+	}
+	partner, err := h.userRepo.GetUser(partnerID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return false
+	}
+	return canMatch(h.config, user.UserAge, partner.UserAge)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"tanysu-bot/config"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAgeConfig() *config.Config {
+	return &config.Config{MinAge: 14, MaxAge: 99, AdultAge: 18}
+}
+
+func TestAgeHint(t *testing.T) {
+	cfg := testAgeConfig()
+	assert.Empty(t, ageHint(cfg, 14))
+	assert.Empty(t, ageHint(cfg, 99))
+	assert.NotEmpty(t, ageHint(cfg, 5))
+	assert.NotEmpty(t, ageHint(cfg, 13))
+	assert.NotEmpty(t, ageHint(cfg, 300))
+}
+
+func TestCanMatch(t *testing.T) {
+	cfg := testAgeConfig()
+	assert.True(t, canMatch(cfg, 15, 17))
+	assert.True(t, canMatch(cfg, 18, 45))
+	// Несовершеннолетний и взрослый — никогда.
+	assert.False(t, canMatch(cfg, 17, 18))
+	assert.False(t, canMatch(cfg, 40, 16))
+	// Возраст вне допустимых границ (например, сохранённый до проверки) не проходит.
+	assert.False(t, canMatch(cfg, 5, 15))
+	assert.False(t, canMatch(cfg, 300, 30))
+	assert.False(t, canMatch(cfg, 0, 30))
+
+	// Границы берутся из настроек.
+	cfg.AdultAge = 16
+	assert.True(t, canMatch(cfg, 17, 30))
+}
+
+func TestMinorRestriction(t *testing.T) {
+	cfg := testAgeConfig()
+
+	assert.Empty(t, minorRestriction(cfg, &models.Message{Text: "Сәлем! Қалайсың?"}))
+	assert.Empty(t, minorRestriction(cfg, &models.Message{Sticker: &models.Sticker{FileID: "s"}}))
+	assert.Empty(t, minorRestriction(cfg, &models.Message{Text: "2009 жылы туғанмын"}))
+	// Даты и цены — не телефоны.
+	for _, text := range []string{
+		"12.05.2009 туған күнім",
+		"2009-05-12 болды",
+		"01.09.2024 - 25.05.2025",
+		"Бағасы 12 500 000 теңге",
+		"1 250 000 ₸ тұрады",
+		"$1,299.99",
+	} {
+		assert.Empty(t, minorRestriction(cfg, &models.Message{Text: text}), text)
+	}
+
+	assert.Equal(t, minorMediaText, minorRestriction(cfg, &models.Message{Photo: []models.PhotoSize{{FileID: "p"}}}))
+	assert.Equal(t, minorMediaText, minorRestriction(cfg, &models.Message{Voice: &models.Voice{FileID: "v"}}))
+	assert.Equal(t, minorMediaText, minorRestriction(cfg, &models.Message{PaidMedia: &models.PaidMediaInfo{StarCount: 10}}))
+
+	for name, msg := range map[string]*models.Message{
+		"contact":  {Contact: &models.Contact{PhoneNumber: "+77001234567"}},
+		"location": {Location: &models.Location{Latitude: 43.2, Longitude: 76.9}},
+		"mention":  {Text: "жаз @someone", Entities: []models.MessageEntity{{Type: models.MessageEntityTypeMention, Offset: 4, Length: 8}}},
+		"phone":    {Text: "нөмірім 8 777 123 45 67"},
+		"phone+":   {Text: "+7 (777) 123-45-67"},
+		"link":     {Text: "https://T.me/someone"},
+		"caption":  {Caption: "instagram.com/someone"},
+	} {
+		assert.Equal(t, minorContactText, minorRestriction(cfg, msg), name)
+	}
+	// Фото с контактами в подписи отклоняется как контакт: это важнее.
+	assert.Equal(t, minorContactText, minorRestriction(cfg, &models.Message{Photo: []models.PhotoSize{{FileID: "p"}}, Caption: "wa.me/77001234567"}))
+
+	cfg.MinorsAllowMedia = true
+	cfg.MinorsAllowContacts = true
+	assert.Empty(t, minorRestriction(cfg, &models.Message{Photo: []models.PhotoSize{{FileID: "p"}}, Caption: "wa.me/77001234567"}))
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// avatarMaxBytes — Bot API отдаёт ботам файлы не больше 20 МБ.
+const avatarMaxBytes = 20 << 20
+
+// fileClient скачивает файлы с серверов Telegram.
+var fileClient = &http.Client{Timeout: time.Minute}
+
+// downloadFile скачивает файл Telegram по file_id.
+func downloadFile(ctx context.Context, b *bot.Bot, fileID string) ([]byte, error) {
+	file, err := b.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.FileDownloadLink(file), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := fileClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, avatarMaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if len(data) > avatarMaxBytes {
+		return nil, fmt.Errorf("failed to download file: larger than %d bytes", avatarMaxBytes)
+	}
+	return data, nil
+}
+
+// saveAvatar скачивает фото, кладёт его в хранилище аватаров (прежний аватар заменяется)
+// и записывает путь и file_id в БД.
+func (h *Handler) saveAvatar(ctx context.Context, b *bot.Bot, userID int64, photo models.PhotoSize) error {
+	data, err := downloadFile(ctx, b, photo.FileID)
+	if err != nil {
+		return err
+	}
+	path, err := h.avatars.Save(ctx, userID, data)
+	if err != nil {
+		return err
+	}
+	return h.userRepo.UpdateAvatar(userID, path, photo.FileID)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"tanysu-bot/internal/repository"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// deleteMessagesBatch — deleteMessages принимает не больше 100 сообщений за раз.
+const deleteMessagesBatch = 100
+
+// trackMessages запоминает сообщения сессии, чтобы их можно было "сжечь".
+func (h *Handler) trackMessages(ctx context.Context, sessionID string, msgs ...*models.Message) {
+	if sessionID == "" {
+		return
+	}
+	for _, msg := range msgs {
+		if msg == nil || msg.ID == 0 {
+			continue
+		}
+		if err := h.chatState.TrackMessage(ctx, sessionID, msg.Chat.ID, msg.ID); err != nil {
+			fmt.Println("Ошибка при сохранении сообщения сессии:", err)
+		}
+	}
+}
+
+// trackChannelMessages запоминает копии сообщений сессии в канале.
+func (h *Handler) trackChannelMessages(ctx context.Context, sessionID string, ids []int) {
+	if sessionID == "" {
+		return
+	}
+	for _, id := range ids {
+		if err := h.chatState.TrackChannelMessage(ctx, sessionID, id); err != nil {
+			fmt.Println("Ошибка при сохранении сообщения канала:", err)
+		}
+	}
+}
+
+// BurnChatHandler удаляет все сообщения текущей или только что завершённой сессии в обоих чатах.
+func (h *Handler) BurnChatHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.CallbackQuery.From.ID
+
+	sessionID, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
+		return
+	}
+	if sessionID == "" {
+		sessionID, err = h.chatState.GetLastSession(ctx, userID)
+		if err != nil {
+			fmt.Println("Ошибка при получении последней сессии:", err)
+			return
+		}
+	}
+	if sessionID == "" {
+		h.answerCallbackAlert(ctx, b, update, "Өшіретін чат табылмады.")
+		return
+	}
+
+	messages, err := h.chatState.TakeSessionMessages(ctx, sessionID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сообщений сессии:", err)
+		return
+	}
+
+	byChat := groupByChat(messages)
+
+	removed := 0
+	for chatID, ids := range byChat {
+		n, _ := h.deleteMessages(ctx, b, chatID, ids)
+		removed += n
+	}
+
+	if h.config.BurnChannelCopies {
+		channelIDs, err := h.chatState.TakeSessionChannelMessages(ctx, sessionID)
+		if err != nil {
+			fmt.Println("Ошибка при получении сообщений канала:", err)
+		} else {
+			n, _ := h.deleteMessages(ctx, b, h.config.ChannelName, channelIDs)
+			removed += n
+		}
+	}
+
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+	})
+
+	for chatID := range byChat {
+		if chatID == userID {
+			continue
+		}
+		h.sendNotice(ctx, b, chatID, "🔥 Сөйлесуші чатты толығымен өшірді.")
+	}
+	h.sendNotice(ctx, b, userID, fmt.Sprintf("🔥 Чат өшірілді. Жойылған хабарламалар саны: %d", removed))
+}
+
+// sendNotice отправляет служебное сообщение через очередь отправки.
+func (h *Handler) sendNotice(ctx context.Context, b *bot.Bot, chatID int64, text string) {
+	_, err := h.outbound.Send(ctx, chatID, func(ctx context.Context) (*models.Message, error) {
+		return b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   text,
+		})
+	})
+	if err != nil {
+		fmt.Println("Ошибка при отправке уведомления:", err)
+	}
+}
+
+// groupByChat раскладывает сообщения по чатам, чтобы удалять их пачками.
+func groupByChat(messages []repository.TrackedMessage) map[int64][]int {
+	byChat := make(map[int64][]int)
+	for _, m := range messages {
+		byChat[m.ChatID] = append(byChat[m.ChatID], m.MessageID)
+	}
+	return byChat
+}
+
+// deleteMessages удаляет сообщения пачками и возвращает, сколько удалось удалить, и сообщения,
+// которые не удалились из-за временной ошибки. Если пачка целиком не удалилась, пробует
+// удалить сообщения по одному.
+func (h *Handler) deleteMessages(ctx context.Context, b *bot.Bot, chatID any, ids []int) (removed int, retry []int) {
+	for start := 0; start < len(ids); start += deleteMessagesBatch {
+		end := min(start+deleteMessagesBatch, len(ids))
+		batch := ids[start:end]
+
+		ok, err := b.DeleteMessages(ctx, &bot.DeleteMessagesParams{
+			ChatID:     chatID,
+			MessageIDs: batch,
+		})
+		if err == nil && ok {
+			removed += len(batch)
+			continue
+		}
+
+		for _, id := range batch {
+			ok, err := b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+				ChatID:    chatID,
+				MessageID: id,
+			})
+			if err != nil {
+				fmt.Println("Ошибка при удалении сообщения:", err)
+				if isTransient(err) {
+					retry = append(retry, id)
+				}
+				continue
+			}
+			if ok {
+				removed++
+			}
+		}
+	}
+	return removed, retry
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"tanysu-bot/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByChat(t *testing.T) {
+	due := []repository.TrackedMessage{
+		{ChatID: 123, MessageID: 1},
+		{ChatID: 456, MessageID: 2},
+		{ChatID: 123, MessageID: 3},
+	}
+	assert.Equal(t, map[int64][]int{123: {1, 3}, 456: {2}}, groupByChat(due))
+	assert.Empty(t, groupByChat(nil))
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"tanysu-bot/internal/dispatcher"
+	"tanysu-bot/internal/repository"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// chatActionRepeat — Telegram показывает действие около 5 секунд,
+// поэтому при долгой отправке повторяем его чуть чаще.
+const chatActionRepeat = 4 * time.Second
+
+// chatActionFor подбирает действие ("отправляет фото…", "записывает голосовое…"),
+// которое увидит собеседник, пока бот пересылает медиа.
+func chatActionFor(msg *models.Message) (models.ChatAction, bool) {
+	switch {
+	case msg.Photo != nil:
+		return models.ChatActionUploadPhoto, true
+	case msg.Video != nil, msg.Animation != nil:
+		return models.ChatActionUploadVideo, true
+	case msg.PaidMedia != nil:
+		return models.ChatActionUploadPhoto, true
+	case msg.Voice != nil:
+		return models.ChatActionRecordVoice, true
+	case msg.VideoNote != nil:
+		return models.ChatActionRecordVideoNote, true
+	case msg.Document != nil, msg.Audio != nil:
+		return models.ChatActionUploadDocument, true
+	case msg.Location != nil:
+		return models.ChatActionFindLocation, true
+	case msg.Sticker != nil:
+		return models.ChatActionChooseSticker, true
+	}
+	return "", false
+}
+
+// startChatAction показывает собеседнику действие и повторяет его, пока не будет вызвана stop.
+func (h *Handler) startChatAction(ctx context.Context, b *bot.Bot, chatID int64, action models.ChatAction) (stop func()) {
+	sendAction := func() {
+		if _, err := b.SendChatAction(ctx, &bot.SendChatActionParams{
+			ChatID: chatID,
+			Action: action,
+		}); err != nil {
+			fmt.Println("Ошибка при отправке действия в чат:", err)
+		}
+	}
+	sendAction()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(chatActionRepeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sendAction()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// sendTypingPulse показывает собеседнику короткое "печатает…", если отправитель включил /typing.
+// Пауза задерживает всю очередь сессии, поэтому, если за сообщением уже ждут другие, её пропускаем.
+func (h *Handler) sendTypingPulse(ctx context.Context, b *bot.Bot, userID, partnerID int64) {
+	if dispatcher.Pending(ctx) > 0 {
+		return
+	}
+	enabled, err := h.chatState.GetUserSetting(ctx, userID, repository.SettingTypingPulse)
+	if err != nil {
+		fmt.Println("Ошибка при чтении настройки typing:", err)
+		return
+	}
+	if !enabled {
+		return
+	}
+
+	if _, err := b.SendChatAction(ctx, &bot.SendChatActionParams{
+		ChatID: partnerID,
+		Action: models.ChatActionTyping,
+	}); err != nil {
+		fmt.Println("Ошибка при отправке действия typing:", err)
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Duration(h.config.TypingPulseMs) * time.Millisecond):
+	}
+}
+
+// TypingToggleHandler включает или выключает "печатает…" для собеседника перед каждым сообщением.
+func (h *Handler) TypingToggleHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	h.toggleSetting(ctx, b, update, repository.SettingTypingPulse,
+		"Енді сөйлесушіңіз сіз жазып жатқаныңызды көреді.",
+		"\"Жазып жатыр…\" белгісі өшірілді.",
+	)
+}
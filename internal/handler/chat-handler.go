@@ -2,44 +2,92 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
+	"sort"
 	"tanysu-bot/config"
+	"tanysu-bot/internal/avatar"
 	"tanysu-bot/internal/keyboard"
 	"tanysu-bot/internal/repository"
+	"tanysu-bot/internal/sender"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
+// deleteTokenTTL — Telegram позволяет боту удалять сообщения только в течение 48 часов.
+const deleteTokenTTL = 48 * time.Hour
+
+// selectTokenTTL — сколько живёт кнопка выбора собеседника из списка.
+const selectTokenTTL = time.Hour
+
 // Handler содержит все методы-обработчики для бота.
 type Handler struct {
 	chatState *repository.ChatRepository
 	userRepo  *repository.UserRepository
 	config    *config.Config
+	// outbound — очередь отправки с учётом лимитов Telegram.
+	outbound *sender.Sender
+	// avatars — хранилище файлов аватаров.
+	avatars avatar.Store
+
+	deleteCallback    *keyboard.Callback[deletePayload]
+	selectCallback    *keyboard.Callback[selectPayload]
+	ephemeralCallback *keyboard.Callback[ephemeralPayload]
+	retryCallback     *keyboard.Callback[retryPayload]
+	purgeCallback     *keyboard.Callback[purgePayload]
+	locationCallback  *keyboard.Callback[locationPayload]
+}
+
+// deletePayload описывает пару сообщений, которую удаляет кнопка удаления.
+type deletePayload struct {
+	OwnerID       int64  `json:"owner_id"`
+	SessionID     string `json:"session_id"`
+	SenderChatID  int64  `json:"sender_chat_id"`
+	SenderMsgID   int    `json:"sender_msg_id"`
+	PartnerChatID int64  `json:"partner_chat_id"`
+	PartnerMsgID  int    `json:"partner_msg_id"`
 }
 
-func NewHandler(chatState *repository.ChatRepository, userRepo *repository.UserRepository, config *config.Config) *Handler {
-	return &Handler{chatState: chatState, userRepo: userRepo, config: config}
+// selectPayload — собеседник, выбранный в списке ChatButtonHandler.
+type selectPayload struct {
+	UserID int64 `json:"user_id"`
+}
+
+func NewHandler(chatState *repository.ChatRepository, userRepo *repository.UserRepository, callbacks *keyboard.CallbackRegistry, outbound *sender.Sender, avatars avatar.Store, config *config.Config) *Handler {
+	return &Handler{
+		chatState:         chatState,
+		userRepo:          userRepo,
+		config:            config,
+		outbound:          outbound,
+		avatars:           avatars,
+		deleteCallback:    keyboard.NewCallback[deletePayload](callbacks, "delete_", keyboard.WithTTL(deleteTokenTTL)),
+		selectCallback:    keyboard.NewCallback[selectPayload](callbacks, "select_", keyboard.WithTTL(selectTokenTTL)),
+		ephemeralCallback: keyboard.NewCallback[ephemeralPayload](callbacks, "ephemeral_", keyboard.WithTTL(ephemeralProposalTTL), keyboard.WithOneTime()),
+		retryCallback:     keyboard.NewCallback[retryPayload](callbacks, "retry_", keyboard.WithTTL(deleteTokenTTL), keyboard.WithOneTime()),
+		purgeCallback:     keyboard.NewCallback[purgePayload](callbacks, "purge_", keyboard.WithTTL(purgeConfirmTTL), keyboard.WithOneTime()),
+		locationCallback:  keyboard.NewCallback[locationPayload](callbacks, "loc_", keyboard.WithTTL(locationConfirmTTL), keyboard.WithOneTime()),
+	}
 }
 
 // ensureUserInDB сохраняет пользователя в БД при первом обращении.
 func (h *Handler) ensureUserInDB(update *models.Update) {
 	var userID int64
-	var username, firstName, lastName string
+	var username, firstName, lastName, languageCode string
 
 	if update.Message != nil {
 		userID = update.Message.From.ID
 		username = update.Message.From.Username
 		firstName = update.Message.From.FirstName
 		lastName = update.Message.From.LastName
+		languageCode = update.Message.From.LanguageCode
 	} else if update.CallbackQuery != nil {
 		userID = update.CallbackQuery.From.ID
 		username = update.CallbackQuery.From.Username
 		firstName = update.CallbackQuery.From.FirstName
 		lastName = update.CallbackQuery.From.LastName
+		languageCode = update.CallbackQuery.From.LanguageCode
 	} else {
 		return
 	}
@@ -51,16 +99,24 @@ func (h *Handler) ensureUserInDB(update *models.Update) {
 	}
 	if !exists {
 		newUser := &repository.User{
-			UserID:    userID,
-			UserName:  username,
-			FirstName: firstName,
-			LastName:  lastName,
+			UserID:       userID,
+			UserName:     username,
+			FirstName:    firstName,
+			LastName:     lastName,
+			LanguageCode: languageCode,
 		}
 		if err := h.userRepo.InsertUser(newUser); err != nil {
 			fmt.Println("Error inserting user:", err)
 		} else {
 			fmt.Printf("User %d inserted into DB\n", userID)
 		}
+		return
+	}
+	// Язык нужен, чтобы писать пользователю, даже когда он сам ничего не прислал.
+	if languageCode != "" {
+		if err := h.userRepo.UpdateLanguageCode(userID, languageCode); err != nil {
+			fmt.Println("Ошибка при сохранении языка:", err)
+		}
 	}
 }
 
@@ -83,181 +139,7 @@ func (h *Handler) CheckRegistration(ctx context.Context, b *bot.Bot, update *mod
 	}
 
 	// Если хоть одно из обязательных полей пустое – регистрация не завершена.
-	if user.AvaFileID == "" || user.UserNickname == "" || user.UserSex == "" || user.UserAge == 0 || user.UserGeo == "" {
-		return false
-	}
-	return true
-}
-
-// RegistrationHandler обрабатывает входящие данные для регистрации пользователя.
-// Ожидается, что пользователь отправит фото с подписью в формате:
-//
-//	@nickname
-//	Еркек        (или "Әйел")
-//	25
-//
-// RegistrationHandler обрабатывает входящие данные для регистрации (или обновления) пользователя.
-// Ожидается, что пользователь отправит фото с caption в формате:
-//
-//	@nickname
-//	Еркек немесе Әйел
-//	25
-//
-// Если формат неверный, бот отправляет сообщение с примером.
-func (h *Handler) RegistrationHandler(ctx context.Context, b *bot.Bot, update *models.Update) bool {
-	var userID int64
-	if update.Message != nil {
-		userID = update.Message.From.ID
-	} else if update.CallbackQuery != nil {
-		userID = update.CallbackQuery.From.ID
-	} else {
-		return false
-	}
-
-	// Если пользователь уже занят (имеется собеседник), регистрация не требуется.
-	partnerID, err := h.chatState.GetUserPartner(ctx, userID)
-	if err != nil {
-		fmt.Println("Ошибка при получении собеседника:", err)
-		return false
-	}
-	if partnerID != 0 {
-		return false
-	}
-
-	user, err := h.userRepo.GetUser(userID)
-	if err != nil {
-		fmt.Println("Ошибка получения пользователя:", err)
-		return false
-	}
-
-	// Если сообщение не содержит ни фото, ни геолокацию – отправляем инструкцию.
-	if update.Message != nil && update.Message.Photo == nil && update.Message.Location == nil {
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: userID,
-			Text:   "Өтінеміз, тіркеу үшін төмендегі үлгіге сәйкес фото жіберіп, оған caption ретінде төмендегідей мәліметтерді енгізіңіз:\n\n@nickname\nЕркек немесе Әйел\n25",
-		})
-		return false
-	}
-
-	// Если получено фото с caption (даже если ранее регистрация была выполнена) – обновляем данные.
-	if update.Message != nil && update.Message.Photo != nil {
-		// Приводим caption к единообразному виду.
-		captionText := update.Message.Caption
-		captionText = strings.ReplaceAll(captionText, "\r\n", "\n")
-		lines := strings.Split(captionText, "\n")
-		fmt.Println("Полученные строки из caption:", lines)
-		if len(lines) < 3 {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Қате формат! Тіркеу үлгісі:\n@nickname\nЕркек немесе Әйел\n25",
-			})
-			return false
-		}
-		// Первая строка – лақап аты (удаляем символ @, если есть).
-		nickname := strings.TrimSpace(lines[0])
-		if strings.HasPrefix(nickname, "@") {
-			nickname = strings.TrimPrefix(nickname, "@")
-		}
-		// Вторая строка – жыныс (ожидаем "Еркек" или "Әйел").
-		gender := strings.TrimSpace(lines[1])
-		if gender != "Еркек" && gender != "Әйел" {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Қате формат! Екінші жолда тек 'Еркек' немесе 'Әйел' болуы тиіс.\nМысал:\n@nickname\nЕркек\n25",
-			})
-			return false
-		}
-		// Третья строка – жас (возраст).
-		ageStr := strings.TrimSpace(lines[2])
-		age, err := strconv.Atoi(ageStr)
-		if err != nil {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Қате формат! Жас саны сан түрінде болуы керек, мысалы: 25\nМысал:\n@nickname\nЕркек\n25",
-			})
-			return false
-		}
-
-		// Проверяем наличие директории для аватарок и создаём её, если отсутствует.
-		avaDir := "./ava"
-		if _, err := os.Stat(avaDir); os.IsNotExist(err) {
-			if err := os.MkdirAll(avaDir, os.ModePerm); err != nil {
-				b.SendMessage(ctx, &bot.SendMessageParams{
-					ChatID: userID,
-					Text:   "Аватар сақталатын директорияны жасау кезінде қате пайда болды.",
-				})
-				return false
-			}
-		}
-		savedPath := fmt.Sprintf("%s/%d.jpg", avaDir, userID)
-		photo := update.Message.Photo[len(update.Message.Photo)-1]
-		// Обновляем аватар (сохраняем путь и FileID).
-		if err := h.userRepo.UpdateAvatar(userID, savedPath, photo.FileID); err != nil {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Аватарды жаңарту қатесі: " + err.Error(),
-			})
-			return false
-		}
-		// Обновляем никнейм, жыныс и жас.
-		if err := h.userRepo.UpdateNickname(userID, nickname); err != nil {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Никнеймді жаңарту қатесі: " + err.Error(),
-			})
-			return false
-		}
-		if err := h.userRepo.UpdateUserSex(userID, gender); err != nil {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Жынысты жаңарту қатесі: " + err.Error(),
-			})
-			return false
-		}
-		if err := h.userRepo.UpdateUserAge(userID, age); err != nil {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Жас жаңарту қатесі: " + err.Error(),
-			})
-			return false
-		}
-
-		// Составляем summary-подпись.
-		summaryCaption := fmt.Sprintf("Лақап атыңыз: @%s\nЖынысыңыз: %s\nЖасыңыз: %d\nЕгер мәліметтеріңізді жаңартқыңыз келсе, қайта жіберіңіз.", nickname, gender, age)
-		// Отправляем фото с summary-подписью.
-		b.SendPhoto(ctx, &bot.SendPhotoParams{
-			ChatID:         userID,
-			Photo:          &models.InputFileString{Data: photo.FileID},
-			Caption:        summaryCaption,
-			ProtectContent: true,
-		})
-		// После успешной регистрации (или обновления) отправляем сообщение с кнопкой для геолокации.
-
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: userID,
-			Text:   "Тіркеу сәтті аяқталды! Орныңызды бөлісу түймесі арқылы партнер таба аласыз!",
-		})
-		return true
-	}
-
-	// Обработка геолокации: если геолокация не заполнена и пришло сообщение с локацией.
-	if user.UserGeo == "" && update.Message != nil && update.Message.Location != nil {
-		geo := fmt.Sprintf("%.5f,%.5f", update.Message.Location.Latitude, update.Message.Location.Longitude)
-		if err := h.userRepo.UpdateUserGeo(userID, geo); err != nil {
-			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: userID,
-				Text:   "Геолокацияны жаңарту қатесі: " + err.Error(),
-			})
-			return false
-		}
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: userID,
-			Text:   "Геолокация сәтті сақталды! Енді сіз собеседник таба аласыз.",
-		})
-		return true
-	}
-
-	return false
+	return profileComplete(user)
 }
 
 func (h *Handler) InlineHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
@@ -271,16 +153,26 @@ func (h *Handler) InlineHandler(ctx context.Context, b *bot.Bot, update *models.
 			ChatID: update.CallbackQuery.From.ID,
 			Text:   "Өтінеміз, геолокацияңызды жіберіңіз.\n(Мысалы, 'геолокация жіберу' батырмасын немесе 'орныңызды бөлісу' функциясын пайдаланыңыз)",
 		})
+		return
 	}
 
 	// Далее обрабатываем остальные callback'и, например, выбор собеседника.
 	if update.CallbackQuery != nil {
-		var selectedUserID int64
-		_, err := fmt.Sscanf(update.CallbackQuery.Data, "select_%d", &selectedUserID)
+		selected, err := h.selectCallback.Decode(ctx, update.CallbackQuery.Data)
 		if err != nil {
 			fmt.Println("Ошибка при чтении выбранного ID:", err)
 			return
 		}
+		selectedUserID := selected.UserID
+
+		// Список мог устареть: возраст проверяем заново перед тем, как соединить.
+		if !h.ageMatch(update.CallbackQuery.From.ID, selectedUserID) {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: update.CallbackQuery.From.ID,
+				Text:   "Бұл сөйлесушімен байланысу мүмкін емес. Басқа сөйлесуші таңдаңыз.",
+			})
+			return
+		}
 
 		ok, err := h.chatState.CheckPartnerToEmpty(ctx, selectedUserID)
 		if err != nil {
@@ -303,14 +195,22 @@ func (h *Handler) InlineHandler(ctx context.Context, b *bot.Bot, update *models.
 			fmt.Println("Ошибка в SetPartner (собеседника):", err)
 			return
 		}
+		if _, err := h.chatState.StartSession(ctx, update.CallbackQuery.From.ID, selectedUserID); err != nil {
+			fmt.Println("Ошибка в StartSession:", err)
+			return
+		}
 
+		// Сначала пишем выбранному собеседнику: если он заблокировал бота, сессия сразу завершится.
+		if _, err := h.sendOrEndChat(ctx, b, &bot.SendMessageParams{
+			ChatID: selectedUserID,
+			Text:   h.connectText(selectedUserID, update.CallbackQuery.From.ID),
+		}, selectedUserID, update.CallbackQuery.From.ID); err != nil {
+			fmt.Println("Ошибка при уведомлении собеседника:", err)
+			return
+		}
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: update.CallbackQuery.From.ID,
-			Text:   fmt.Sprintf("Вы подключены к собеседнику с ID: %d", selectedUserID),
-		})
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: selectedUserID,
-			Text:   fmt.Sprintf("Вы подключены к собеседнику с ID: %d", update.CallbackQuery.From.ID),
+			Text:   h.connectText(update.CallbackQuery.From.ID, selectedUserID),
 		})
 	}
 }
@@ -328,28 +228,34 @@ func (h *Handler) CallbackHandlerExit(ctx context.Context, b *bot.Bot, update *m
 
 	kb := keyboard.NewKeyboard()
 	kb.AddRow(keyboard.NewInlineButton("💬 Chat", "chat"))
+	kb.AddRow(keyboard.NewInlineButton("🔥 Өртеу", "burn"))
 
+	burnKb := keyboard.NewKeyboard()
+	burnKb.AddRow(keyboard.NewInlineButton("🔥 Өртеу", "burn"))
+
+	h.stopLiveLocations(ctx, b, userID)
 	if err := h.chatState.RemoveUser(ctx, userID); err != nil {
 		fmt.Println("Ошибка при удалении пользователя:", err)
 		return
 	}
 
 	if partnerID != 0 {
+		h.stopLiveLocations(ctx, b, partnerID)
 		if err := h.chatState.RemoveUser(ctx, partnerID); err != nil {
 			fmt.Println("Ошибка при удалении собеседника:", err)
 			return
 		}
-		b.SendMessage(ctx, &bot.SendMessageParams{
+		h.sendOrEndChat(ctx, b, &bot.SendMessageParams{
 			ChatID:      partnerID,
 			Text:        "Ваш собеседник покинул чат.",
 			ReplyMarkup: kb.Build(),
-		})
+		}, partnerID, 0)
 	}
 
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:      userID,
 		Text:        "Вы вышли из чата.",
-		ReplyMarkup: nil,
+		ReplyMarkup: burnKb.Build(),
 	})
 }
 
@@ -363,6 +269,10 @@ func (h *Handler) ChatButtonHandler(ctx context.Context, b *bot.Bot, update *mod
 		fmt.Println("Ошибка при добавлении пользователя в чат:", err)
 		return
 	}
+	// Пользователь снова пишет боту — значит, больше не блокирует его.
+	if err := h.userRepo.SetUserActive(userID, true); err != nil {
+		fmt.Println("Ошибка при включении пользователя:", err)
+	}
 
 	users, err := h.chatState.GetUsers(ctx)
 	if err != nil {
@@ -370,14 +280,68 @@ func (h *Handler) ChatButtonHandler(ctx context.Context, b *bot.Bot, update *mod
 		return
 	}
 
-	kb := keyboard.NewKeyboard()
+	me, err := h.userRepo.GetUser(userID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return
+	}
+	if hint := ageHint(h.config, me.UserAge); hint != "" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   hint + " Жасыңызды /edit арқылы түзетіңіз.",
+		})
+		return
+	}
+
+	var candidates []*repository.User
 	for _, u := range users {
-		if u != userID {
-			kb.AddRow(keyboard.NewInlineButton(fmt.Sprintf("User %d", u), fmt.Sprintf("select_%d", u)))
+		if u == userID {
+			continue
+		}
+		active, err := h.userRepo.IsUserActive(u)
+		if err != nil {
+			fmt.Println("Ошибка при проверке пользователя:", err)
+			continue
+		}
+		if !active {
+			continue
+		}
+		candidate, err := h.userRepo.GetUser(u)
+		if err != nil {
+			fmt.Println("Ошибка получения пользователя:", err)
+			continue
+		}
+		// Несовершеннолетним показываем только несовершеннолетних, взрослым — только взрослых.
+		if !canMatch(h.config, me.UserAge, candidate.UserAge) {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	// Сначала показываем собеседников из того же города.
+	if me.UserCity != "" {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].UserCity == me.UserCity && candidates[j].UserCity != me.UserCity
+		})
+	}
+
+	kb := keyboard.NewKeyboard()
+	for _, c := range candidates {
+		label := fmt.Sprintf("User %d", c.UserID)
+		if c.UserCity != "" {
+			label += " · " + c.UserCity
+		}
+		if distance := distanceText(me, c); distance != "" {
+			label += " · " + distance
+		}
+		button, err := h.selectCallback.Button(ctx, label, selectPayload{UserID: c.UserID})
+		if err != nil {
+			fmt.Println("Ошибка при создании кнопки выбора:", err)
+			return
 		}
+		kb.AddRow(button)
 	}
 
-	if len(users) == 1 {
+	if len(candidates) == 0 {
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: userID,
 			Text:   "Нет доступных пользователей для подключения. Подождите...",
@@ -393,7 +357,7 @@ func (h *Handler) ChatButtonHandler(ctx context.Context, b *bot.Bot, update *mod
 }
 
 // MessageHandler перенаправляет текстовые сообщения между собеседниками.
-// Если пользователь свободен и регистрация не завершена, сначала обрабатываются регистрационные данные.
+// Если пользователь свободен и регистрация не завершена, сообщение считается ответом мастеру регистрации.
 func (h *Handler) MessageHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	h.ensureUserInDB(update)
 
@@ -404,6 +368,11 @@ func (h *Handler) MessageHandler(ctx context.Context, b *bot.Bot, update *models
 		userID = update.CallbackQuery.From.ID
 	}
 
+	// После нажатия поля в /edit следующее сообщение — его новое значение, а не реплика в чат.
+	if update.Message != nil && h.handleProfileEdit(ctx, b, update.Message) {
+		return
+	}
+
 	partnerID, err := h.chatState.GetUserPartner(ctx, userID)
 	if err != nil {
 		fmt.Println("Ошибка получения собеседника:", err)
@@ -415,13 +384,8 @@ func (h *Handler) MessageHandler(ctx context.Context, b *bot.Bot, update *models
 	}
 
 	if !h.CheckRegistration(ctx, b, update) {
-		if h.RegistrationHandler(ctx, b, update) {
-			if !h.CheckRegistration(ctx, b, update) {
-				return
-			}
-		} else {
-			return
-		}
+		h.RegistrationHandler(ctx, b, update)
+		return
 	}
 
 	h.HandleChat(ctx, b, update, h.chatState)
@@ -445,35 +409,59 @@ func (h *Handler) HelloHandler(ctx context.Context, b *bot.Bot, update *models.U
 	}
 }
 
+// deleteRejection проверяет, что кнопку удаления нажал её владелец в той же сессии,
+// где она была создана. Возвращает текст отказа или пустую строку.
+func deleteRejection(target *deletePayload, userID int64, sessionID string) string {
+	if target.OwnerID != userID {
+		return "Бұл хабарламаны тек жіберуші өшіре алады."
+	}
+	if sessionID != target.SessionID {
+		return "Бұл батырманың мерзімі өтті."
+	}
+	return ""
+}
+
+// DeleteMessageHandler удаляет пару сообщений по токену из callback data.
+// Токен выдаётся только отправителю и действует только в рамках той сессии, где был создан.
 func (h *Handler) DeleteMessageHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	var senderChatID int64
-	var senderMsgID int
-	var partnerChatID int64
-	var partnerMsgID int
+	responseChatID := update.CallbackQuery.From.ID
 
-	_, err := fmt.Sscanf(update.CallbackQuery.Data, "delete_%d_%d_%d_%d", &senderChatID, &senderMsgID, &partnerChatID, &partnerMsgID)
+	target, err := h.deleteCallback.Decode(ctx, update.CallbackQuery.Data)
+	if errors.Is(err, keyboard.ErrCallbackExpired) {
+		h.answerCallbackAlert(ctx, b, update, "Бұл батырманың мерзімі өтті.")
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при получении цели удаления:", err)
+		return
+	}
+	sessionID, err := h.chatState.GetSession(ctx, responseChatID)
 	if err != nil {
-		fmt.Println("Ошибка при извлечении данных из callback:", err)
+		fmt.Println("Ошибка при получении сессии:", err)
+		return
+	}
+	if reason := deleteRejection(target, responseChatID, sessionID); reason != "" {
+		fmt.Printf("Отклонено удаление: владелец %d, нажал %d\n", target.OwnerID, responseChatID)
+		h.answerCallbackAlert(ctx, b, update, reason)
 		return
 	}
 
 	okSend, errSender := b.DeleteMessage(ctx, &bot.DeleteMessageParams{
-		ChatID:    senderChatID,
-		MessageID: senderMsgID,
+		ChatID:    target.SenderChatID,
+		MessageID: target.SenderMsgID,
 	})
 	if errSender != nil {
 		fmt.Println("Ошибка при удалении сообщения отправителя:", errSender)
 	}
 
 	okPartner, errPartner := b.DeleteMessage(ctx, &bot.DeleteMessageParams{
-		ChatID:    partnerChatID,
-		MessageID: partnerMsgID,
+		ChatID:    target.PartnerChatID,
+		MessageID: target.PartnerMsgID,
 	})
 	if errPartner != nil {
 		fmt.Println("Ошибка при удалении сообщения собеседника:", errPartner)
 	}
 
-	responseChatID := update.CallbackQuery.From.ID
 	if !okSend || !okPartner {
 		b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: responseChatID,
@@ -481,16 +469,29 @@ func (h *Handler) DeleteMessageHandler(ctx context.Context, b *bot.Bot, update *
 		})
 		return
 	}
+	if err := h.deleteCallback.Forget(ctx, update.CallbackQuery.Data); err != nil {
+		fmt.Println("Ошибка при удалении токена:", err)
+	}
 	b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: responseChatID,
 		Text:   "Хабарлама сәтті өшірілді!",
 	})
 }
 
+// answerCallbackAlert отвечает на нажатие кнопки всплывающим предупреждением.
+func (h *Handler) answerCallbackAlert(ctx context.Context, b *bot.Bot, update *models.Update, text string) {
+	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+		Text:            text,
+		ShowAlert:       true,
+	})
+	if err != nil {
+		fmt.Println("Ошибка при ответе на callback:", err)
+	}
+}
+
 // HandleChat осуществляет передачу сообщений между собеседниками и пересылает их в канал.
 func (h *Handler) HandleChat(ctx context.Context, b *bot.Bot, update *models.Update, chatState *repository.ChatRepository) {
-	ForwardChannelID := h.config.ChannelName
-
 	userID := update.Message.From.ID
 	partnerID, err := chatState.GetUserPartner(ctx, userID)
 	if err != nil {
@@ -514,606 +515,250 @@ func (h *Handler) HandleChat(ctx context.Context, b *bot.Bot, update *models.Upd
 		return
 	}
 
-	kb := keyboard.NewKeyboard()
-	kb.AddRow(keyboard.NewInlineButton("🔕 Шығу", "exit"))
-
-	senderIdentifier := ""
-	if update.Message.From.Username != "" {
-		senderIdentifier = "@" + update.Message.From.Username
-	} else {
-		senderIdentifier = fmt.Sprintf("%d", update.Message.From.ID)
+	// В чате с несовершеннолетним медиа и контакты по умолчанию не пересылаются.
+	if !h.checkMinorChat(ctx, b, update.Message, partnerID) {
+		return
 	}
-	var partnerIdentifier string
-	if partnerID != 0 {
-		partnerIdentifier = fmt.Sprintf("%d", partnerID)
-	} else {
-		partnerIdentifier = "сөйлесуші жоқ"
+
+	sessionID, err := chatState.GetSession(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
 	}
 
-	var caption string
-	if update.Message.Caption != "" {
-		caption = fmt.Sprintf("%s: %s", senderIdentifier, update.Message.Caption)
+	// Точную геолокацию пересылаем только после того, как отправитель выберет точность.
+	if h.needsLocationConfirm(update.Message) {
+		h.askLocationConfirm(ctx, b, update.Message, sessionID)
+		return
 	}
 
-	switch {
-	case update.Message.Text != "":
-		fmt.Printf("TEXT | User=%s | Text=%q\n", senderIdentifier, update.Message.Text)
-		partnerMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         partnerID,
-			Text:           fmt.Sprintf("%s: %s", senderIdentifier, update.Message.Text),
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("error in send text", err)
-			return
-		}
+	h.relay(ctx, b, update.Message, partnerID, sessionID)
+}
 
-		// Отправляем сообщение отправителю с кнопкой удаления.
-		// Здесь update.Message.Chat.ID — это чат отправителя.
-		senderMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			// Пока клавиатура пустая – далее мы сформируем callback data с обоими ID.
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке сообщения отправителю:", err)
+// relay доставляет сообщение собеседнику: через очередь, если в ней что-то ждёт, иначе сразу.
+func (h *Handler) relay(ctx context.Context, b *bot.Bot, msg *models.Message, partnerID int64, sessionID string) {
+	userID := msg.From.ID
+
+	// Пока в очереди есть недоставленные сообщения, новое встаёт за ними, чтобы их не обогнать.
+	if sessionID != "" {
+		if pending, err := h.chatState.OutboxLen(ctx, sessionID); err != nil {
+			fmt.Println("Ошибка при проверке очереди доставки:", err)
+		} else if pending > 0 {
+			fmt.Println("OUTBOX | в очереди", pending)
+			h.queueForDelivery(ctx, b, msg, partnerID, sessionID, 0)
 			return
 		}
+	}
 
-		// Формируем callback data, включающую оба chatID и оба messageID.
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Хабарламыны жою!", callbackData))
-
-		// Редактируем отправленное сообщение отправителю, добавляя клавиатуру с кнопкой удаления.
-		// Если метод редактирования не поддерживается, можно просто отправить новое сообщение.
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
-
-		textToChannel := fmt.Sprintf("Сообщение от %s к %s:\n%s", senderIdentifier, partnerIdentifier, update.Message.Text)
-		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         ForwardChannelID,
-			Text:           textToChannel,
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка пересылки сообщения:", err)
-		}
-	case update.Message.Photo != nil:
-		fmt.Printf("PHOTO | User=%s | FileID=%s | Caption=%q\n",
-			senderIdentifier,
-			update.Message.Photo[len(update.Message.Photo)-1].FileID,
-			update.Message.Caption,
-		)
-		photoID := update.Message.Photo[len(update.Message.Photo)-1].FileID
-		partnerMsg, err := b.SendPhoto(ctx, &bot.SendPhotoParams{
-			ChatID:         partnerID,
-			Photo:          &models.InputFileString{Data: photoID},
-			Caption:        withDefaultCaption(senderIdentifier, caption, "фото"),
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке фото собеседнику:", err)
-			return
-		}
-		senderMsg, err := b.SendPhoto(ctx, &bot.SendPhotoParams{
-			ChatID:         update.Message.Chat.ID,
-			Photo:          &models.InputFileString{Data: photoID},
-			Caption:        "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке фото отправителю:", err)
-			return
-		}
+	err := h.deliver(ctx, b, msg, partnerID, sessionID)
+	switch {
+	case err == nil:
+	case isChatGone(err):
+		h.endDeadChat(ctx, b, partnerID, userID)
+	case isTransient(err) && sessionID != "":
+		h.queueForDelivery(ctx, b, msg, partnerID, sessionID, 1)
+	default:
+		h.trackMessages(ctx, sessionID, h.sendDeliveryFailed(ctx, b, msg, sessionID))
+	}
+}
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Фотоны жою!", callbackData))
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
+// deliver пересылает сообщение собеседнику и, если это удалось, отвечает отправителю и копирует
+// сообщение в канал. Возвращает ошибку отправки собеседнику.
+func (h *Handler) deliver(ctx context.Context, b *bot.Bot, msg *models.Message, partnerID int64, sessionID string) error {
+	ForwardChannelID := h.config.ChannelName
+	userID := msg.From.ID
 
-		photoCaption := withDefaultCaption(senderIdentifier, caption, "фото")
-		captionToChannel := fmt.Sprintf("Сообщение от %s к %s:\n%s", senderIdentifier, partnerIdentifier, photoCaption)
-		b.SendPhoto(ctx, &bot.SendPhotoParams{
-			ChatID:         ForwardChannelID,
-			Photo:          &models.InputFileString{Data: photoID},
-			Caption:        captionToChannel,
-			ProtectContent: true,
-		})
-		// 3. Видео.
-	case update.Message.Video != nil:
-		fmt.Printf("VIDEO | User=%s | FileID=%s | Caption=%q\n",
-			senderIdentifier,
-			update.Message.Video.FileID,
-			update.Message.Caption,
-		)
-		videoCaption := withDefaultCaption(senderIdentifier, caption, "видео")
-		partnerMsg, err := b.SendVideo(ctx, &bot.SendVideoParams{
-			ChatID:         partnerID,
-			Video:          &models.InputFileString{Data: update.Message.Video.FileID},
-			Caption:        videoCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке видео отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendVideo(ctx, &bot.SendVideoParams{
-			ChatID:         update.Message.Chat.ID,
-			Video:          &models.InputFileString{Data: update.Message.Video.FileID},
-			Caption:        videoCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке видео отправителю:", err)
-			return
-		}
+	kb := keyboard.NewKeyboard()
+	kb.AddRow(
+		keyboard.NewInlineButton("🔕 Шығу", "exit"),
+		keyboard.NewInlineButton("🔥 Өртеу", "burn"),
+	)
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Видеоны жою!", callbackData))
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
-		captionToChannel := fmt.Sprintf("Сообщение от %s к %s:\n%s", senderIdentifier, partnerIdentifier, videoCaption)
-		b.SendVideo(ctx, &bot.SendVideoParams{
-			ChatID:         ForwardChannelID,
-			Video:          &models.InputFileString{Data: update.Message.Video.FileID},
-			Caption:        captionToChannel,
-			ProtectContent: true,
-		})
+	senderIdentifier := ""
+	if msg.From.Username != "" {
+		senderIdentifier = "@" + msg.From.Username
+	} else {
+		senderIdentifier = fmt.Sprintf("%d", msg.From.ID)
+	}
+	partnerIdentifier := fmt.Sprintf("%d", partnerID)
 
-	// 4. Голосовое сообщение.
-	case update.Message.Voice != nil:
-		fmt.Printf("VOICE | User=%s | FileID=%s | Caption=%q\n",
-			senderIdentifier,
-			update.Message.Voice.FileID,
-			update.Message.Caption,
-		)
-		voiceCaption := withDefaultCaption(senderIdentifier, caption, "голосовое сообщение")
-		partnerMsg, err := b.SendVoice(ctx, &bot.SendVoiceParams{
-			ChatID:         partnerID,
-			Voice:          &models.InputFileString{Data: update.Message.Voice.FileID},
-			Caption:        voiceCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Дыбыстық хабарламаны отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendVoice(ctx, &bot.SendVoiceParams{
-			ChatID:         update.Message.Chat.ID,
-			Voice:          &models.InputFileString{Data: update.Message.Voice.FileID},
-			Caption:        voiceCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Дыбыстық хабарламаны отправителю:", err)
-			return
-		}
+	ephemeralTTL, err := h.chatState.GetSessionEphemeral(ctx, sessionID)
+	if err != nil {
+		fmt.Println("Ошибка при чтении режима ephemeral:", err)
+	}
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Дыбыстық хабарламаны жою!", callbackData))
+	// В режиме самоудаляющихся сообщений медиа скрыто под спойлером.
+	content, ok := newRelayContent(msg, senderIdentifier, partnerIdentifier, ephemeralTTL > 0, h.config.DiceReroll)
+	if !ok {
+		fmt.Printf("UNKNOWN | User=%s\n", senderIdentifier)
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
-		captionToChannel := fmt.Sprintf("Сообщение от %s к %s:\n%s", senderIdentifier, partnerIdentifier, voiceCaption)
-		b.SendVoice(ctx, &bot.SendVoiceParams{
-			ChatID:         ForwardChannelID,
-			Voice:          &models.InputFileString{Data: update.Message.Voice.FileID},
-			Caption:        captionToChannel,
-			ProtectContent: true,
-		})
-
-	// 5. Видео-сообщение (VideoNote).
-	case update.Message.VideoNote != nil:
-		fmt.Printf("VIDEO_NOTE | User=%s | FileID=%s\n",
-			senderIdentifier,
-			update.Message.VideoNote.FileID,
-		)
-		partnerMsg, err := b.SendVideoNote(ctx, &bot.SendVideoNoteParams{
-			ChatID:         partnerID,
-			VideoNote:      &models.InputFileString{Data: update.Message.VideoNote.FileID},
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Видео хабарламаны сообшение отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendVideoNote(ctx, &bot.SendVideoNoteParams{
-			ChatID:         update.Message.Chat.ID,
-			VideoNote:      &models.InputFileString{Data: update.Message.VideoNote.FileID},
+			ChatID:         userID,
+			Text:           "Неизвестный тип сообщения. Попробуйте отправить текст, фото, видео, голосовое сообщение или документ.",
 			ReplyMarkup:    kb.Build(),
 			ProtectContent: true,
 		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Видео хабарламаны отправителю:", err)
-			return
-		}
+		return nil
+	}
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Видео хабарламаны жою!", callbackData))
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
-		captionToChannel := fmt.Sprintf("Сообщение от %s к %s: Видео сообщение", senderIdentifier, partnerIdentifier)
-		b.SendVideoNote(ctx, &bot.SendVideoNoteParams{
-			ChatID:         ForwardChannelID,
-			VideoNote:      &models.InputFileString{Data: update.Message.VideoNote.FileID},
-			ProtectContent: true,
-		})
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         ForwardChannelID,
-			Text:           captionToChannel,
-			ProtectContent: true,
-		})
+	echo, err := h.chatState.GetUserSetting(ctx, userID, repository.SettingEcho)
+	if err != nil {
+		fmt.Println("Ошибка при чтении настройки echo:", err)
+	}
 
-	// 6. Документ.
-	case update.Message.Document != nil:
-		fmt.Printf("DOCUMENT | User=%s | FileID=%s | Caption=%q\n",
-			senderIdentifier,
-			update.Message.Document.FileID,
-			update.Message.Caption,
-		)
-		docCaption := withDefaultCaption(senderIdentifier, caption, "документ")
-		partnerMsg, err := b.SendDocument(ctx, &bot.SendDocumentParams{
-			ChatID:         partnerID,
-			Document:       &models.InputFileString{Data: update.Message.Document.FileID},
-			Caption:        docCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Құжатты отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendDocument(ctx, &bot.SendDocumentParams{
-			ChatID:         update.Message.Chat.ID,
-			Document:       &models.InputFileString{Data: update.Message.Document.FileID},
-			Caption:        docCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
+	// Пока медиа пересылается, собеседник видит соответствующее действие.
+	stopAction := func() {}
+	if action, ok := chatActionFor(msg); ok {
+		stopAction = h.startChatAction(ctx, b, partnerID, action)
+	} else {
+		h.sendTypingPulse(ctx, b, userID, partnerID)
+	}
+	defer stopAction()
+
+	// Копия собеседнику и эхо-копия отправителю уходят одновременно:
+	// задержка у собеседника зависит только от его отправки.
+	var (
+		partnerMsg, senderCopy *models.Message
+		partnerErr             error
+		copies                 fanOut
+	)
+	copies.Go("собеседнику", func() error {
+		partnerMsg, partnerErr = h.outbound.Send(ctx, partnerID, func(ctx context.Context) (*models.Message, error) {
+			return content.copyTo(ctx, b, partnerID, kb.Build())
+		})
+		stopAction()
+		return partnerErr
+	})
+	if echo {
+		copies.Go("копия отправителю", func() error {
+			var err error
+			senderCopy, err = h.outbound.Send(ctx, msg.Chat.ID, func(ctx context.Context) (*models.Message, error) {
+				return content.copyTo(ctx, b, msg.Chat.ID, kb.Build())
+			})
+			return err
 		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Құжатты отправителю:", err)
-			return
+	}
+	if err := copies.Wait(); err != nil {
+		fmt.Println("Ошибка при пересылке сообщения:", err)
+	}
+	if partnerErr != nil {
+		// Собеседник сообщение не получил — эхо-копия только запутает отправителя.
+		if senderCopy != nil {
+			b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+				ChatID:    msg.Chat.ID,
+				MessageID: senderCopy.ID,
+			})
 		}
+		return partnerErr
+	}
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Құжатты жою!", callbackData))
-		// Редактируем отправленное сообщение отправителю, добавляя клавиатуру с кнопкой удаления.
-		// Если метод редактирования не поддерживается, можно просто отправить новое сообщение.
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
-
-		captionToChannel := fmt.Sprintf("Сообщение от %s к %s:\n%s", senderIdentifier, partnerIdentifier, docCaption)
-		b.SendDocument(ctx, &bot.SendDocumentParams{
-			ChatID:         ForwardChannelID,
-			Document:       &models.InputFileString{Data: update.Message.Document.FileID},
-			Caption:        captionToChannel,
-			ProtectContent: true,
-		})
-
-	// 7. Аудио.
-	case update.Message.Audio != nil:
-		fmt.Printf("AUDIO | User=%s | FileID=%s | Caption=%q\n",
-			senderIdentifier,
-			update.Message.Audio.FileID,
-			update.Message.Caption,
-		)
-		audioCaption := withDefaultCaption(senderIdentifier, caption, "аудио")
-		partnerMsg, err := b.SendAudio(ctx, &bot.SendAudioParams{
-			ChatID:         partnerID,
-			Audio:          &models.InputFileString{Data: update.Message.Audio.FileID},
-			Caption:        audioCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Аудионы отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendAudio(ctx, &bot.SendAudioParams{
-			ChatID:         update.Message.Chat.ID,
-			Audio:          &models.InputFileString{Data: update.Message.Audio.FileID},
-			Caption:        audioCaption,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Аудионы отправителю:", err)
-			return
-		}
+	// keep запоминает сообщения для "сжигания" и, если включён режим ephemeral, ставит их на удаление.
+	keep := func(msgs ...*models.Message) {
+		h.trackMessages(ctx, sessionID, msgs...)
+		h.scheduleDeletion(ctx, ephemeralTTL, msgs...)
+	}
+	keep(msg, partnerMsg, senderCopy)
+	h.rememberLiveLocation(ctx, msg, partnerMsg, sessionID)
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Аудионы жою!", callbackData))
-		// Редактируем отправленное сообщение отправителю, добавляя клавиатуру с кнопкой удаления.
-		// Если метод редактирования не поддерживается, можно просто отправить новое сообщение.
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
-		captionToChannel := fmt.Sprintf("Сообщение от %s к %s:\n%s", senderIdentifier, partnerIdentifier, audioCaption)
-		b.SendAudio(ctx, &bot.SendAudioParams{
-			ChatID:         ForwardChannelID,
-			Audio:          &models.InputFileString{Data: update.Message.Audio.FileID},
-			Caption:        captionToChannel,
-			ProtectContent: true,
-		})
+	target := &deletePayload{
+		OwnerID:       userID,
+		SessionID:     sessionID,
+		SenderChatID:  msg.Chat.ID,
+		SenderMsgID:   msg.ID,
+		PartnerChatID: partnerID,
+		PartnerMsgID:  partnerMsg.ID,
+	}
 
-	// 8. Локация.
-	case update.Message.Location != nil:
-		fmt.Printf("LOCATION | User=%s | Lat=%.5f | Long=%.5f\n",
-			senderIdentifier,
-			update.Message.Location.Latitude,
-			update.Message.Location.Longitude,
+	// Только кнопке удаления нужны ID отправленных копий; остальное уходит параллельно с ней.
+	var wiring fanOut
+	wiring.Go("кнопка удаления", func() error {
+		var (
+			prompt *models.Message
+			err    error
 		)
-		partnerMsg, err := b.SendLocation(ctx, &bot.SendLocationParams{
-			ChatID:         partnerID,
-			Latitude:       update.Message.Location.Latitude,
-			Longitude:      update.Message.Location.Longitude,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Гео-локацияны отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendLocation(ctx, &bot.SendLocationParams{
-			ChatID:         update.Message.Chat.ID,
-			Latitude:       update.Message.Location.Latitude,
-			Longitude:      update.Message.Location.Longitude,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Гео-локацияны отправителю:", err)
-			return
+		if senderCopy != nil {
+			// В режиме эха кнопка удаляет копию, а не исходное сообщение.
+			target.SenderMsgID = senderCopy.ID
+			prompt, err = h.sendEchoPrompt(ctx, b, msg, target, content)
+		} else {
+			prompt, err = h.sendDeleteReply(ctx, b, msg, target, content)
 		}
-
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Гео-локацияны жою!", callbackData))
-		// Редактируем отправленное сообщение отправителю, добавляя клавиатуру с кнопкой удаления.
-		// Если метод редактирования не поддерживается, можно просто отправить новое сообщение.
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
+		keep(prompt)
+		return err
+	})
+	if msg.Poll != nil {
+		wiring.Go("опрос", func() error {
+			keep(h.linkPoll(ctx, b, msg, partnerMsg, senderCopy, content)...)
+			return nil
 		})
+	}
+	h.sendToChannel(b, sessionID, ForwardChannelID, content)
 
-		locationText := fmt.Sprintf("Сообщение от %s к %s:\nЛокация: %.5f, %.5f",
-			senderIdentifier, partnerIdentifier, update.Message.Location.Latitude, update.Message.Location.Longitude)
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         ForwardChannelID,
-			Text:           locationText,
-			ProtectContent: true,
-		})
+	if err := wiring.Wait(); err != nil {
+		fmt.Println("Ошибка при ответе отправителю:", err)
+	}
+	return nil
+}
 
-	// 9. Стикер.
-	case update.Message.Sticker != nil:
-		fmt.Printf("STICKER | User=%s | FileID=%s\n",
-			senderIdentifier,
-			update.Message.Sticker.FileID,
-		)
-		partnerMsg, err := b.SendSticker(ctx, &bot.SendStickerParams{
-			ChatID:         partnerID,
-			Sticker:        &models.InputFileString{Data: update.Message.Sticker.FileID},
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Стикерді отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendSticker(ctx, &bot.SendStickerParams{
-			ChatID:         update.Message.Chat.ID,
-			Sticker:        &models.InputFileString{Data: update.Message.Sticker.FileID},
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
+// sendToChannel ставит копии сообщения в очередь отправки в канал, не дожидаясь её:
+// собеседники не должны ждать канал, который Telegram ограничивает строже личных чатов.
+func (h *Handler) sendToChannel(b *bot.Bot, sessionID, channel string, content *relayContent) {
+	for _, req := range content.toChannel(b, channel) {
+		err := h.outbound.Go(channel, req, func(sent *models.Message, err error) {
+			if err != nil {
+				fmt.Println("Ошибка пересылки в канал:", err)
+				return
+			}
+			h.trackChannelMessages(context.Background(), sessionID, []int{sent.ID})
 		})
 		if err != nil {
-			fmt.Println("Ошибка при отправке Стикерді отправителю:", err)
-			return
+			fmt.Println("Ошибка пересылки в канал:", err)
 		}
+	}
+}
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Стикерді жою!", callbackData))
-		// Редактируем отправленное сообщение отправителю, добавляя клавиатуру с кнопкой удаления.
-		// Если метод редактирования не поддерживается, можно просто отправить новое сообщение.
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
-
-		b.SendSticker(ctx, &bot.SendStickerParams{
-			ChatID:         ForwardChannelID,
-			Sticker:        &models.InputFileString{Data: update.Message.Sticker.FileID},
-			ProtectContent: true,
-		})
-		stickerInfo := fmt.Sprintf("Сообщение от %s к %s: Стикер", senderIdentifier, partnerIdentifier)
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         ForwardChannelID,
-			Text:           stickerInfo,
-			ProtectContent: true,
-		})
-
-	// 10. Контакт.
-	case update.Message.Contact != nil:
-		contact := update.Message.Contact
-		fmt.Printf("CONTACT | User=%s | Phone=%s | FirstName=%s | LastName=%s\n",
-			senderIdentifier,
-			contact.PhoneNumber,
-			contact.FirstName,
-			contact.LastName,
-		)
-		contactText := fmt.Sprintf("%s отправил(а) контакт:\nТел: %s\nИмя: %s %s",
-			senderIdentifier,
-			contact.PhoneNumber,
-			contact.FirstName,
-			contact.LastName,
-		)
-		partnerMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         partnerID,
-			Text:           contactText,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Контактіні отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           contactText,
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке Контактіні отправителю:", err)
-			return
-		}
+// deleteKeyboard сохраняет цель удаления в реестре кнопок и возвращает клавиатуру с коротким ключом.
+func (h *Handler) deleteKeyboard(ctx context.Context, target *deletePayload, label string) (*keyboard.Keyboard, error) {
+	button, err := h.deleteCallback.Button(ctx, label, *target)
+	if err != nil {
+		return nil, err
+	}
+	deleteKb := keyboard.NewKeyboard()
+	deleteKb.AddRow(button)
+	return deleteKb, nil
+}
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Контактіні жою!", callbackData))
-		// Редактируем отправленное сообщение отправителю, добавляя клавиатуру с кнопкой удаления.
-		// Если метод редактирования не поддерживается, можно просто отправить новое сообщение.
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
-			ReplyMarkup:    deleteKb.Build(),
-			ProtectContent: true,
-		})
+// sendDeleteReply отвечает на исходное сообщение отправителя отметкой о доставке с кнопкой удаления.
+// Само сообщение отправителя не трогаем — кнопка удалит его вместе с копией у собеседника.
+func (h *Handler) sendDeleteReply(ctx context.Context, b *bot.Bot, msg *models.Message, target *deletePayload, content *relayContent) (*models.Message, error) {
+	deleteKb, err := h.deleteKeyboard(ctx, target, content.deleteLabel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store delete token: %w", err)
+	}
 
-		channelContactText := fmt.Sprintf("Сообщение от %s к %s:\nКонтакт:\nТел: %s\nИмя: %s %s",
-			senderIdentifier,
-			partnerIdentifier,
-			contact.PhoneNumber,
-			contact.FirstName,
-			contact.LastName,
-		)
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         ForwardChannelID,
-			Text:           channelContactText,
-			ProtectContent: true,
+	return h.outbound.Send(ctx, msg.Chat.ID, func(ctx context.Context) (*models.Message, error) {
+		return b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:              msg.Chat.ID,
+			Text:                deliveredText,
+			ReplyParameters:     &models.ReplyParameters{MessageID: msg.ID, AllowSendingWithoutReply: true},
+			ReplyMarkup:         deleteKb.Build(),
+			DisableNotification: true,
+			ProtectContent:      true,
 		})
+	})
+}
 
-	// 11. Опрос.
-	case update.Message.Poll != nil:
-		poll := update.Message.Poll
-		fmt.Printf("POLL | User=%s | Question=%q | Options=%d\n",
-			senderIdentifier,
-			poll.Question,
-			len(poll.Options),
-		)
-		var pollOptions []models.InputPollOption
-		for _, o := range poll.Options {
-			pollOptions = append(pollOptions, models.InputPollOption{Text: o.Text})
-		}
-		partnerMsg, err := b.SendPoll(ctx, &bot.SendPollParams{
-			ChatID:         partnerID,
-			Question:       poll.Question,
-			Options:        pollOptions,
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке опрос отправителю:", err)
-			return
-		}
-		senderMsg, err := b.SendPoll(ctx, &bot.SendPollParams{
-			ChatID:         update.Message.Chat.ID,
-			Question:       poll.Question,
-			Options:        pollOptions,
-			ProtectContent: true,
-		})
-		if err != nil {
-			fmt.Println("Ошибка при отправке опрос отправителю:", err)
-			return
-		}
+// sendEchoPrompt отправляет под эхо-копией отметку о доставке с кнопкой удаления (режим /echo).
+func (h *Handler) sendEchoPrompt(ctx context.Context, b *bot.Bot, msg *models.Message, target *deletePayload, content *relayContent) (*models.Message, error) {
+	deleteKb, err := h.deleteKeyboard(ctx, target, content.deleteLabel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store delete token: %w", err)
+	}
 
-		callbackData := fmt.Sprintf("delete_%d_%d_%d_%d", update.Message.Chat.ID, senderMsg.ID, partnerID, partnerMsg.ID)
-		deleteKb := keyboard.NewKeyboard()
-		deleteKb.AddRow(keyboard.NewInlineButton("⛔️ Хабарламыны жою опрос!", callbackData))
-		// Редактируем отправленное сообщение отправителю, добавляя клавиатуру с кнопкой удаления.
-		// Если метод редактирования не поддерживается, можно просто отправить новое сообщение.
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         update.Message.Chat.ID,
-			Text:           "Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
+	return h.outbound.Send(ctx, msg.Chat.ID, func(ctx context.Context) (*models.Message, error) {
+		return b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:         msg.Chat.ID,
+			Text:           deliveredText + " Егер хабарламаны өшіргіңіз келсе, төмендегі батырманы басыңыз.",
 			ReplyMarkup:    deleteKb.Build(),
 			ProtectContent: true,
 		})
-
-		pollText := fmt.Sprintf("Сообщение от %s к %s: Опрос\nВопрос: %s",
-			senderIdentifier, partnerIdentifier, poll.Question)
-		b.SendPoll(ctx, &bot.SendPollParams{
-			ChatID:         ForwardChannelID,
-			Question:       poll.Question,
-			Options:        pollOptions,
-			ProtectContent: true,
-		})
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         ForwardChannelID,
-			Text:           pollText,
-			ProtectContent: true,
-		})
-
-	// 12. Неизвестный тип сообщения.
-	default:
-		fmt.Printf("UNKNOWN | User=%s\n", senderIdentifier)
-		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:         userID,
-			Text:           "Неизвестный тип сообщения. Попробуйте отправить текст, фото, видео, голосовое сообщение или документ.",
-			ReplyMarkup:    kb.Build(),
-			ProtectContent: true,
-		})
-	}
-}
-
-// withDefaultCaption формирует подпись для медиа-сообщения, если она отсутствует.
-func withDefaultCaption(username, caption, mediaType string) string {
-	if caption != "" {
-		return caption
-	}
-	return fmt.Sprintf("@%s отправил(а) %s", username, mediaType)
+	})
 }
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteRejection(t *testing.T) {
+	target := &deletePayload{
+		OwnerID:       123,
+		SessionID:     "s1",
+		SenderChatID:  123,
+		SenderMsgID:   1,
+		PartnerChatID: 456,
+		PartnerMsgID:  2,
+	}
+
+	assert.Empty(t, deleteRejection(target, 123, "s1"))
+	// Собеседник не может удалить чужое сообщение, даже в той же сессии.
+	assert.Equal(t, "Бұл хабарламаны тек жіберуші өшіре алады.", deleteRejection(target, 456, "s1"))
+	// Кнопка из прошлой сессии больше не действует.
+	assert.Equal(t, "Бұл батырманың мерзімі өтті.", deleteRejection(target, 123, "s2"))
+	assert.Equal(t, "Бұл батырманың мерзімі өтті.", deleteRejection(target, 123, ""))
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tanysu-bot/internal/geo"
+	"tanysu-bot/internal/keyboard"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// cityPickerText — кнопка шага "Орны", открывающая выбор города вместо отправки геолокации.
+const cityPickerText = "🏙 Қаланы таңдау"
+
+// Callback data выбора города. Названия берутся из встроенного справочника и помещаются в 64 байта.
+const (
+	cityAreasData  = "city:areas"
+	cityAreaPrefix = "city:a:"
+	cityPickPrefix = "city:c:"
+)
+
+// pickerKeyboard раскладывает кнопки по две в ряд.
+func pickerKeyboard(buttons []models.InlineKeyboardButton) *keyboard.Keyboard {
+	kb := keyboard.NewKeyboard()
+	for i := 0; i < len(buttons); i += 2 {
+		kb.AddRow(buttons[i:min(i+2, len(buttons))]...)
+	}
+	return kb
+}
+
+func cityAreasKeyboard() *models.InlineKeyboardMarkup {
+	var buttons []models.InlineKeyboardButton
+	for _, area := range geo.Areas() {
+		buttons = append(buttons, keyboard.NewInlineButton(area, cityAreaPrefix+area))
+	}
+	return pickerKeyboard(buttons).Build()
+}
+
+func citiesKeyboard(cities []geo.City) *models.InlineKeyboardMarkup {
+	var buttons []models.InlineKeyboardButton
+	for _, city := range cities {
+		buttons = append(buttons, keyboard.NewInlineButton(city.Name, cityPickPrefix+city.Name))
+	}
+	kb := pickerKeyboard(buttons)
+	kb.AddRow(keyboard.NewInlineButton(registrationBackText, cityAreasData))
+	return kb.Build()
+}
+
+// sendCityPicker показывает список областей и соседних стран.
+func (h *Handler) sendCityPicker(ctx context.Context, b *bot.Bot, userID int64) {
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        "Облысыңызды немесе еліңізді таңдаңыз:",
+		ReplyMarkup: cityAreasKeyboard(),
+	})
+}
+
+// CityPickerHandler переключает списки выбора города и сохраняет выбранный город.
+func (h *Handler) CityPickerHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	data := update.CallbackQuery.Data
+	picker := update.CallbackQuery.Message.Message
+
+	switch {
+	case data == cityAreasData && picker != nil:
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      picker.Chat.ID,
+			MessageID:   picker.ID,
+			Text:        "Облысыңызды немесе еліңізді таңдаңыз:",
+			ReplyMarkup: cityAreasKeyboard(),
+		})
+	case strings.HasPrefix(data, cityAreaPrefix) && picker != nil:
+		cities := geo.CitiesIn(strings.TrimPrefix(data, cityAreaPrefix))
+		if len(cities) == 0 {
+			h.answerCallbackAlert(ctx, b, update, "Бұл тізім ескірген, қайта ашыңыз.")
+			return
+		}
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      picker.Chat.ID,
+			MessageID:   picker.ID,
+			Text:        "Қалаңызды таңдаңыз:",
+			ReplyMarkup: citiesKeyboard(cities),
+		})
+	case strings.HasPrefix(data, cityPickPrefix):
+		city, ok := geo.FindCity(strings.TrimPrefix(data, cityPickPrefix))
+		if !ok {
+			h.answerCallbackAlert(ctx, b, update, "Бұл тізім ескірген, қайта ашыңыз.")
+			return
+		}
+		h.pickCity(ctx, b, update, city)
+		return
+	}
+
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+	})
+}
+
+// saveErrorText — ответ на кнопку, если состояние пользователя не удалось прочитать или сохранить.
+const saveErrorText = "Деректерді сақтау кезінде қате пайда болды, қайталап көріңіз."
+
+// pickCity сохраняет выбранный город как приблизительное местоположение и продолжает
+// регистрацию или /edit, в зависимости от того, где пользователь открыл выбор.
+func (h *Handler) pickCity(ctx context.Context, b *bot.Bot, update *models.Update, city geo.City) {
+	userID := update.CallbackQuery.From.ID
+
+	editField, err := h.chatState.GetEditField(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении редактируемого поля:", err)
+		h.answerCallbackAlert(ctx, b, update, saveErrorText)
+		return
+	}
+	regStep, err := h.chatState.GetRegistrationStep(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении шага регистрации:", err)
+		h.answerCallbackAlert(ctx, b, update, saveErrorText)
+		return
+	}
+	const locationStep = "location"
+	if editField != locationStep && regStep != locationStep {
+		h.answerCallbackAlert(ctx, b, update, "Бұл таңдау енді қолжетімсіз.")
+		return
+	}
+
+	if err := h.saveApproxLocation(userID, city); err != nil {
+		fmt.Println("Ошибка при сохранении города:", err)
+		h.answerCallbackAlert(ctx, b, update, saveErrorText)
+		return
+	}
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+	})
+	if picker := update.CallbackQuery.Message.Message; picker != nil {
+		b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    picker.Chat.ID,
+			MessageID: picker.ID,
+			Text:      "📍 " + city.Name + ", " + city.Area(),
+		})
+	}
+
+	// /edit проверяется первым, как и в MessageHandler.
+	if editField == locationStep {
+		h.profileEditDone(ctx, b, userID)
+		return
+	}
+	h.registrationStepDone(ctx, b, userID, registrationStepIndex(locationStep))
+}
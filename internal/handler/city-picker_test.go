@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"strings"
+	"tanysu-bot/internal/geo"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCityPickerKeyboards(t *testing.T) {
+	areas := cityAreasKeyboard()
+	total := 0
+	for _, row := range areas.InlineKeyboard {
+		assert.LessOrEqual(t, len(row), 2)
+		for _, button := range row {
+			// Telegram ограничивает callback data 64 байтами.
+			assert.LessOrEqual(t, len(button.CallbackData), 64, button.CallbackData)
+			assert.True(t, strings.HasPrefix(button.CallbackData, cityAreaPrefix))
+
+			area := strings.TrimPrefix(button.CallbackData, cityAreaPrefix)
+			cities := citiesKeyboard(geo.CitiesIn(area))
+			for _, cityRow := range cities.InlineKeyboard {
+				for _, cityButton := range cityRow {
+					assert.LessOrEqual(t, len(cityButton.CallbackData), 64, cityButton.CallbackData)
+				}
+			}
+			// Последний ряд — возврат к списку областей.
+			last := cities.InlineKeyboard[len(cities.InlineKeyboard)-1]
+			assert.Equal(t, cityAreasData, last[0].CallbackData)
+			total++
+		}
+	}
+	assert.Equal(t, len(geo.Areas()), total)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tanysu-bot/internal/keyboard"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// isChatGone проверяет, что отправка не удалась навсегда: пользователь заблокировал бота,
+// удалил аккаунт или чат больше не существует.
+func isChatGone(err error) bool {
+	if errors.Is(err, bot.ErrorForbidden) {
+		return true
+	}
+	return errors.Is(err, bot.ErrorBadRequest) && strings.Contains(err.Error(), "chat not found")
+}
+
+// endDeadChat завершает сессию с недоступным пользователем: убирает его из чата и из подбора
+// собеседников и сообщает об этом оставшемуся пользователю (survivorID = 0 — сообщать некому).
+func (h *Handler) endDeadChat(ctx context.Context, b *bot.Bot, deadID, survivorID int64) {
+	fmt.Printf("[LOG] UserID=%d недоступен, сессия завершена\n", deadID)
+
+	h.stopLiveLocations(ctx, b, deadID)
+	if err := h.chatState.RemoveUser(ctx, deadID); err != nil {
+		fmt.Println("Ошибка при удалении пользователя:", err)
+	}
+	if err := h.userRepo.SetUserActive(deadID, false); err != nil {
+		fmt.Println("Ошибка при отключении пользователя:", err)
+	}
+	if survivorID == 0 {
+		return
+	}
+	h.stopLiveLocations(ctx, b, survivorID)
+	if err := h.chatState.RemoveUser(ctx, survivorID); err != nil {
+		fmt.Println("Ошибка при удалении собеседника:", err)
+	}
+
+	kb := keyboard.NewKeyboard()
+	kb.AddRow(keyboard.NewInlineButton("💬 Chat", "chat"))
+	kb.AddRow(keyboard.NewInlineButton("🔥 Өртеу", "burn"))
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      survivorID,
+		Text:        "Сөйлесуші ботты бұғаттады немесе аккаунтын жойды, чат аяқталды.",
+		ReplyMarkup: kb.Build(),
+	})
+}
+
+// sendOrEndChat отправляет служебное сообщение собеседнику и завершает сессию, если он недоступен.
+func (h *Handler) sendOrEndChat(ctx context.Context, b *bot.Bot, params *bot.SendMessageParams, partnerID, userID int64) (*models.Message, error) {
+	msg, err := b.SendMessage(ctx, params)
+	if err != nil && isChatGone(err) {
+		h.endDeadChat(ctx, b, partnerID, userID)
+	}
+	return msg, err
+}
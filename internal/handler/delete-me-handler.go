@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tanysu-bot/internal/keyboard"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// purgeConfirmTTL — сколько действует кнопка подтверждения /delete_me.
+const purgeConfirmTTL = 10 * time.Minute
+
+// purgePayload — ответ на вопрос /delete_me.
+type purgePayload struct {
+	UserID  int64 `json:"user_id"`
+	Confirm bool  `json:"confirm"`
+}
+
+// DeleteMeHandler спрашивает подтверждение перед удалением всех данных пользователя (/delete_me).
+func (h *Handler) DeleteMeHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+
+	yes, err := h.purgeCallback.Button(ctx, "🗑 Иә, бәрін өшіру", purgePayload{UserID: userID, Confirm: true})
+	if err != nil {
+		fmt.Println("Ошибка при создании кнопки удаления аккаунта:", err)
+		return
+	}
+	no, err := h.purgeCallback.Button(ctx, "Жоқ", purgePayload{UserID: userID})
+	if err != nil {
+		fmt.Println("Ошибка при создании кнопки удаления аккаунта:", err)
+		return
+	}
+	kb := keyboard.NewKeyboard()
+	kb.AddRow(yes, no)
+
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        "Барлық деректеріңіз өшіріледі: анкета, аватар, баптаулар және ағымдағы чат. Бұл әрекетті қайтару мүмкін емес. Жалғастырасыз ба?",
+		ReplyMarkup: kb.Build(),
+	})
+}
+
+// DeleteMeAnswerHandler обрабатывает ответ на вопрос /delete_me.
+func (h *Handler) DeleteMeAnswerHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.CallbackQuery.From.ID
+
+	payload, err := h.purgeCallback.Decode(ctx, update.CallbackQuery.Data)
+	if errors.Is(err, keyboard.ErrCallbackExpired) {
+		h.answerCallbackAlert(ctx, b, update, "Бұл батырманың мерзімі өтті. /delete_me командасын қайта жіберіңіз.")
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении ответа на удаление аккаунта:", err)
+		return
+	}
+	if payload.UserID != userID {
+		h.answerCallbackAlert(ctx, b, update, "Бұл батырма сізге арналмаған.")
+		return
+	}
+
+	// Второй кнопкой воспользоваться уже нельзя.
+	if question := update.CallbackQuery.Message.Message; question != nil {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    question.Chat.ID,
+			MessageID: question.ID,
+		})
+	}
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+	})
+
+	if !payload.Confirm {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Өшіру тоқтатылды, деректеріңіз сақталды.",
+		})
+		return
+	}
+
+	if err := h.purgeUser(ctx, b, userID); err != nil {
+		fmt.Println("Ошибка при удалении данных пользователя:", err)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Деректерді өшіру кезінде қате пайда болды, кейінірек қайталап көріңіз.",
+		})
+		return
+	}
+	fmt.Printf("PURGE | User=%d\n", userID)
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        "Деректеріңіз толық өшірілді. Қайта оралсаңыз, тіркеуден жаңадан өтесіз.",
+		ReplyMarkup: keyboard.RemoveReplyKeyboard(),
+	})
+}
+
+// purgeUser завершает чат пользователя и удаляет всё, что о нём хранится: строку в users,
+// аватар и ключи в Redis. Payload кнопок в callback:* удаляются сами по TTL.
+func (h *Handler) purgeUser(ctx context.Context, b *bot.Bot, userID int64) error {
+	partnerID, err := h.chatState.GetUserPartner(ctx, userID)
+	if err != nil {
+		return err
+	}
+	sessionID, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Недоставленные сообщения сессии собеседнику уже не нужны.
+	if sessionID != "" {
+		if err := h.chatState.ClearOutbox(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+	h.stopLiveLocations(ctx, b, userID)
+	if partnerID != 0 {
+		h.stopLiveLocations(ctx, b, partnerID)
+		if err := h.chatState.RemoveUser(ctx, partnerID); err != nil {
+			return err
+		}
+		kb := keyboard.NewKeyboard()
+		kb.AddRow(keyboard.NewInlineButton("💬 Chat", "chat"))
+		kb.AddRow(keyboard.NewInlineButton("🔥 Өртеу", "burn"))
+		h.sendOrEndChat(ctx, b, &bot.SendMessageParams{
+			ChatID:      partnerID,
+			Text:        "Сөйлесушіңіз чаттан шықты.",
+			ReplyMarkup: kb.Build(),
+		}, partnerID, 0)
+	}
+
+	if err := h.chatState.PurgeUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := h.avatars.Delete(ctx, userID); err != nil {
+		return err
+	}
+	return h.userRepo.DeleteUser(userID)
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tanysu-bot/internal/keyboard"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// deliveredText — отметка об успешной доставке собеседнику.
+const deliveredText = "✓ Жеткізілді."
+
+// retryPayload — сообщение, которое не удалось доставить; кнопка повтора пересылает его заново.
+type retryPayload struct {
+	SenderID  int64  `json:"sender_id"`
+	SessionID string `json:"session_id"`
+	Message   []byte `json:"message"`
+}
+
+// sendDeliveryFailed отвечает на сообщение отправителя, что оно не доставлено, и предлагает повторить.
+func (h *Handler) sendDeliveryFailed(ctx context.Context, b *bot.Bot, msg *models.Message, sessionID string) *models.Message {
+	params := &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		Text:            "⚠️ Хабарлама сөйлесушіге жеткізілмеді.",
+		ReplyParameters: &models.ReplyParameters{MessageID: msg.ID, AllowSendingWithoutReply: true},
+		ProtectContent:  true,
+	}
+
+	data, err := encodeMessage(msg)
+	if err != nil {
+		fmt.Println("Ошибка при сохранении сообщения для повтора:", err)
+	} else if button, err := h.retryCallback.Button(ctx, "🔁 Қайталау", retryPayload{
+		SenderID:  msg.From.ID,
+		SessionID: sessionID,
+		Message:   data,
+	}); err != nil {
+		fmt.Println("Ошибка при создании кнопки повтора:", err)
+	} else {
+		kb := keyboard.NewKeyboard()
+		kb.AddRow(button)
+		params.ReplyMarkup = kb.Build()
+		params.Text += " Қайта жіберу үшін батырманы басыңыз."
+	}
+
+	notice, err := b.SendMessage(ctx, params)
+	if err != nil {
+		fmt.Println("Ошибка при отправке уведомления о недоставке:", err)
+		return nil
+	}
+	return notice
+}
+
+// sendChatEndedUndelivered отвечает на сообщение, что оно не доставлено, потому что чат закончился.
+// Кнопки повтора нет: RetryDeliveryHandler всё равно отклонит завершённую сессию.
+func (h *Handler) sendChatEndedUndelivered(ctx context.Context, b *bot.Bot, msg *models.Message) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		Text:            "⚠️ Хабарлама жеткізілмеді: чат аяқталды.",
+		ReplyParameters: &models.ReplyParameters{MessageID: msg.ID, AllowSendingWithoutReply: true},
+		ProtectContent:  true,
+	})
+	if err != nil {
+		fmt.Println("Ошибка при отправке уведомления о недоставке:", err)
+	}
+}
+
+// RetryDeliveryHandler заново пересылает недоставленное сообщение, если сессия ещё та же.
+func (h *Handler) RetryDeliveryHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.CallbackQuery.From.ID
+
+	payload, err := h.retryCallback.Decode(ctx, update.CallbackQuery.Data)
+	if errors.Is(err, keyboard.ErrCallbackExpired) {
+		h.answerCallbackAlert(ctx, b, update, "Бұл хабарламаны қайта жіберу мүмкін емес.")
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении повтора:", err)
+		return
+	}
+
+	sessionID, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
+		return
+	}
+	if payload.SenderID != userID || sessionID == "" || sessionID != payload.SessionID {
+		h.answerCallbackAlert(ctx, b, update, "Чат аяқталды, хабарламаны қайта жіберу мүмкін емес.")
+		return
+	}
+
+	msg, err := decodeMessage(payload.Message)
+	if err != nil {
+		fmt.Println("Ошибка при чтении сообщения для повтора:", err)
+		return
+	}
+
+	// Уведомление о сбое больше не нужно: при новой неудаче придёт новое.
+	if notice := update.CallbackQuery.Message.Message; notice != nil {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    notice.Chat.ID,
+			MessageID: notice.ID,
+		})
+	}
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+	})
+
+	// Геолокация уже прошла подтверждение точности, поэтому сообщение идёт мимо HandleChat.
+	partnerID, err := h.chatState.GetUserPartner(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении собеседника:", err)
+		return
+	}
+	if partnerID == 0 {
+		return
+	}
+	// Возраст собеседников мог измениться с первой попытки: ограничения проверяем заново.
+	if !h.checkMinorChat(ctx, b, msg, partnerID) {
+		return
+	}
+	h.relay(ctx, b, msg, partnerID, sessionID)
+}
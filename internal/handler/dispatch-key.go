@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// DispatchKey возвращает ключ очереди диспетчера: у собеседников в сессии очередь общая,
+// чтобы сообщение одного не обгоняло "exit" другого; вне сессии — очередь пользователя.
+func (h *Handler) DispatchKey(ctx context.Context, update *models.Update) string {
+	userID := updateUserID(update)
+	if userID == 0 {
+		return ""
+	}
+	sessionID, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
+	}
+	if sessionID != "" {
+		return "session:" + sessionID
+	}
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// updateUserID возвращает пользователя, от которого пришло обновление, или 0.
+func updateUserID(update *models.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	case update.EditedMessage != nil && update.EditedMessage.From != nil:
+		return update.EditedMessage.From.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	case update.PollAnswer != nil && update.PollAnswer.User != nil:
+		return update.PollAnswer.User.ID
+	}
+	return 0
+}
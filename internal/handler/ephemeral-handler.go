@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tanysu-bot/internal/keyboard"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	// defaultEphemeralTTL — время жизни сообщений, если в /ephemeral не указан срок.
+	defaultEphemeralTTL = time.Minute
+	minEphemeralTTL     = 5 * time.Second
+	maxEphemeralTTL     = 24 * time.Hour
+
+	// ephemeralProposalTTL — сколько собеседник может отвечать на предложение режима.
+	ephemeralProposalTTL = 10 * time.Minute
+
+	// deletionSchedulerInterval — как часто планировщик проверяет очередь удаления.
+	deletionSchedulerInterval = time.Second
+	deletionSchedulerBatch    = 100
+	// deletionRetryDelay — через сколько повторить удаление после временной ошибки.
+	deletionRetryDelay = 10 * time.Second
+)
+
+// ephemeralPayload — предложение включить самоудаляющиеся сообщения, ожидающее ответа собеседника.
+type ephemeralPayload struct {
+	SessionID  string `json:"session_id"`
+	ProposerID int64  `json:"proposer_id"`
+	Seconds    int64  `json:"seconds"`
+	Accept     bool   `json:"accept"`
+}
+
+// parseEphemeralArg разбирает аргумент /ephemeral: пусто — срок по умолчанию, "off" — выключить,
+// иначе длительность в формате time.ParseDuration от minEphemeralTTL до maxEphemeralTTL.
+func parseEphemeralArg(arg string) (ttl time.Duration, off bool, ok bool) {
+	arg = strings.TrimSpace(arg)
+	switch arg {
+	case "":
+		return defaultEphemeralTTL, false, true
+	case "off":
+		return 0, true, true
+	}
+	ttl, err := time.ParseDuration(arg)
+	if err != nil || ttl < minEphemeralTTL || ttl > maxEphemeralTTL {
+		return 0, false, false
+	}
+	return ttl, false, true
+}
+
+// EphemeralHandler обрабатывает /ephemeral [30s|5m|off].
+// Включение режима требует согласия собеседника, выключить его может любой из двоих.
+func (h *Handler) EphemeralHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+
+	sessionID, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
+		return
+	}
+	partnerID, err := h.chatState.GetUserPartner(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении собеседника:", err)
+		return
+	}
+	if sessionID == "" || partnerID == 0 {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Бұл режим тек сөйлесу кезінде қосылады.",
+		})
+		return
+	}
+
+	ttl, off, ok := parseEphemeralArg(strings.TrimPrefix(update.Message.Text, "/ephemeral"))
+	if !ok {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Қате формат! Мысалы: /ephemeral 30s немесе /ephemeral 5m (5 секундтан 24 сағатқа дейін).",
+		})
+		return
+	}
+	if off {
+		if err := h.chatState.SetSessionEphemeral(ctx, sessionID, 0); err != nil {
+			fmt.Println("Ошибка при выключении режима:", err)
+			return
+		}
+		for _, id := range []int64{userID, partnerID} {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: id,
+				Text:   "⏳ Өзін-өзі жоятын хабарламалар режимі өшірілді.",
+			})
+		}
+		return
+	}
+
+	proposal := ephemeralPayload{SessionID: sessionID, ProposerID: userID, Seconds: int64(ttl / time.Second)}
+	accept := proposal
+	accept.Accept = true
+	acceptButton, err := h.ephemeralCallback.Button(ctx, "✅ Келісемін", accept)
+	if err != nil {
+		fmt.Println("Ошибка при создании кнопки:", err)
+		return
+	}
+	declineButton, err := h.ephemeralCallback.Button(ctx, "❌ Жоқ", proposal)
+	if err != nil {
+		fmt.Println("Ошибка при создании кнопки:", err)
+		return
+	}
+	kb := keyboard.NewKeyboard()
+	kb.AddRow(acceptButton, declineButton)
+
+	if _, err := h.sendOrEndChat(ctx, b, &bot.SendMessageParams{
+		ChatID:      partnerID,
+		Text:        fmt.Sprintf("⏳ Сөйлесуші өзін-өзі жоятын хабарламалар режимін ұсынады: әр хабарлама %s кейін екі жақта да өшіріледі. Келісесіз бе?", formatTTL(ttl)),
+		ReplyMarkup: kb.Build(),
+	}, partnerID, userID); err != nil {
+		fmt.Println("Ошибка при отправке предложения:", err)
+		return
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: userID,
+		Text:   "Ұсыныс сөйлесушіге жіберілді. Ол келіскен соң режим қосылады.",
+	})
+}
+
+// EphemeralAnswerHandler принимает ответ собеседника на предложение /ephemeral.
+func (h *Handler) EphemeralAnswerHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.CallbackQuery.From.ID
+
+	proposal, err := h.ephemeralCallback.Decode(ctx, update.CallbackQuery.Data)
+	if errors.Is(err, keyboard.ErrCallbackExpired) {
+		h.answerCallbackAlert(ctx, b, update, "Бұл ұсыныстың мерзімі өтті.")
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении ответа на ephemeral:", err)
+		return
+	}
+
+	// Отвечать может только собеседник предложившего и только в той же сессии.
+	sessionID, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
+		return
+	}
+	if userID == proposal.ProposerID || sessionID != proposal.SessionID {
+		h.answerCallbackAlert(ctx, b, update, "Бұл ұсыныстың мерзімі өтті.")
+		return
+	}
+
+	if msg := update.CallbackQuery.Message.Message; msg != nil {
+		b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+			ChatID:    msg.Chat.ID,
+			MessageID: msg.ID,
+		})
+	}
+
+	if !proposal.Accept {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: proposal.ProposerID,
+			Text:   "Сөйлесуші өзін-өзі жоятын хабарламалар режимінен бас тартты.",
+		})
+		return
+	}
+
+	ttl := time.Duration(proposal.Seconds) * time.Second
+	if err := h.chatState.SetSessionEphemeral(ctx, sessionID, ttl); err != nil {
+		fmt.Println("Ошибка при включении режима:", err)
+		return
+	}
+	for _, id := range []int64{userID, proposal.ProposerID} {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: id,
+			Text:   fmt.Sprintf("⏳ Режим қосылды: хабарламалар %s кейін екі жақта да өшіріледі. Өшіру үшін: /ephemeral off", formatTTL(ttl)),
+		})
+	}
+}
+
+// scheduleDeletion ставит сообщения в очередь на удаление через ttl; ttl = 0 — режим выключен.
+func (h *Handler) scheduleDeletion(ctx context.Context, ttl time.Duration, msgs ...*models.Message) {
+	if ttl <= 0 {
+		return
+	}
+	at := time.Now().Add(ttl)
+	for _, msg := range msgs {
+		if msg == nil || msg.ID == 0 {
+			continue
+		}
+		if err := h.chatState.ScheduleDeletion(ctx, msg.Chat.ID, msg.ID, at); err != nil {
+			fmt.Println("Ошибка при планировании удаления:", err)
+		}
+	}
+}
+
+// RunDeletionScheduler удаляет сообщения, срок жизни которых истёк. Очередь хранится в Redis,
+// поэтому после перезапуска бота удаление продолжается с того же места.
+func (h *Handler) RunDeletionScheduler(ctx context.Context, b *bot.Bot) {
+	ticker := time.NewTicker(deletionSchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			due, err := h.chatState.DueDeletions(ctx, time.Now(), deletionSchedulerBatch)
+			if err != nil {
+				fmt.Println("Ошибка при чтении очереди удаления:", err)
+			}
+			for chatID, ids := range groupByChat(due) {
+				_, retry := h.deleteMessages(ctx, b, chatID, ids)
+				h.finishDeletions(ctx, chatID, ids, retry)
+			}
+		}
+	}
+}
+
+// finishDeletions убирает обработанные сообщения из очереди удаления. Сообщения, которые
+// не удалились из-за временной ошибки, остаются в очереди и удаляются повторно чуть позже.
+func (h *Handler) finishDeletions(ctx context.Context, chatID int64, ids, retry []int) {
+	pending := make(map[int]bool, len(retry))
+	for _, id := range retry {
+		pending[id] = true
+	}
+	for _, id := range ids {
+		var err error
+		if pending[id] {
+			err = h.chatState.ScheduleDeletion(ctx, chatID, id, time.Now().Add(deletionRetryDelay))
+		} else {
+			err = h.chatState.CompleteDeletion(ctx, chatID, id)
+		}
+		if err != nil {
+			fmt.Println("Ошибка при обновлении очереди удаления:", err)
+		}
+	}
+}
+
+// formatTTL выводит срок в секундах или минутах.
+func formatTTL(ttl time.Duration) string {
+	switch {
+	case ttl%time.Hour == 0:
+		return fmt.Sprintf("%d сағат", int(ttl/time.Hour))
+	case ttl%time.Minute == 0:
+		return fmt.Sprintf("%d минут", int(ttl/time.Minute))
+	default:
+		return fmt.Sprintf("%d секунд", int(ttl/time.Second))
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"tanysu-bot/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEphemeralArg(t *testing.T) {
+	tests := []struct {
+		arg string
+		ttl time.Duration
+		off bool
+		ok  bool
+	}{
+		{"", defaultEphemeralTTL, false, true},
+		{"   ", defaultEphemeralTTL, false, true},
+		{" off", 0, true, true},
+		{"30s", 30 * time.Second, false, true},
+		{" 5m", 5 * time.Minute, false, true},
+		{"1h30m", 90 * time.Minute, false, true},
+		{"5s", minEphemeralTTL, false, true},
+		{"24h", maxEphemeralTTL, false, true},
+		{"4s", 0, false, false},
+		{"25h", 0, false, false},
+		{"-1m", 0, false, false},
+		{"30", 0, false, false},
+		{"OFF", 0, false, false},
+		{"бес минут", 0, false, false},
+	}
+	for _, tt := range tests {
+		ttl, off, ok := parseEphemeralArg(tt.arg)
+		assert.Equal(t, tt.ok, ok, tt.arg)
+		assert.Equal(t, tt.off, off, tt.arg)
+		assert.Equal(t, tt.ttl, ttl, tt.arg)
+	}
+}
+
+func TestScheduleDeletion(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	h := &Handler{chatState: repository.NewRedisClient(client)}
+	ctx := context.Background()
+	defer client.FlushDB(ctx)
+
+	msg := &models.Message{ID: 10, Chat: models.Chat{ID: 123}}
+	copied := &models.Message{ID: 20, Chat: models.Chat{ID: 456}}
+
+	// Режим выключен — в очередь ничего не попадает.
+	h.scheduleDeletion(ctx, 0, msg)
+	count, err := client.ZCard(ctx, "chat:deletions").Result()
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	before := time.Now()
+	h.scheduleDeletion(ctx, 30*time.Second, msg, nil, &models.Message{}, copied)
+
+	due, err := h.chatState.DueDeletions(ctx, before.Add(29*time.Second), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = h.chatState.DueDeletions(ctx, time.Now().Add(31*time.Second), 10)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []repository.TrackedMessage{{ChatID: 123, MessageID: 10}, {ChatID: 456, MessageID: 20}}, due)
+}
+
+func TestFormatTTL(t *testing.T) {
+	assert.Equal(t, "30 секунд", formatTTL(30*time.Second))
+	assert.Equal(t, "5 минут", formatTTL(5*time.Minute))
+	assert.Equal(t, "2 сағат", formatTTL(2*time.Hour))
+	assert.Equal(t, "90 секунд", formatTTL(90*time.Second))
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// exportAvatarName — имя файла аватара внутри архива /mydata.
+const exportAvatarName = "avatar.jpg"
+
+// dataExport — всё, что бот хранит о пользователе. Данные собеседников сюда не попадают:
+// о сессиях выгружаются только их идентификаторы.
+type dataExport struct {
+	ExportedAt time.Time        `json:"exported_at"`
+	Profile    exportProfile    `json:"profile"`
+	Settings   map[string]bool  `json:"settings"`
+	Sessions   exportSessions   `json:"sessions"`
+	PollVotes  map[string][]int `json:"poll_votes"`
+	// Avatar — имя файла аватара в архиве, если он сохранён.
+	Avatar string `json:"avatar,omitempty"`
+}
+
+type exportProfile struct {
+	UserID       int64  `json:"user_id"`
+	UserName     string `json:"user_name"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Nickname     string `json:"nickname"`
+	Sex          string `json:"sex"`
+	Age          int    `json:"age"`
+	Geo          string `json:"geo"`
+	City         string `json:"city"`
+	Bio          string `json:"bio"`
+	Contact      string `json:"contact"`
+	AvatarFileID string `json:"avatar_file_id"`
+	LanguageCode string `json:"language_code"`
+	// GeoApprox — вместо координат сохранён центр города, выбранного из списка.
+	GeoApprox bool `json:"geo_approx"`
+	// BlockedBot — пользователь заблокировал бота, и его не показывают в списке собеседников.
+	BlockedBot bool `json:"blocked_bot"`
+}
+
+type exportSessions struct {
+	Current string `json:"current,omitempty"`
+	Last    string `json:"last,omitempty"`
+	InQueue bool   `json:"in_queue"`
+}
+
+// MyDataHandler собирает данные пользователя в ZIP-архив и отправляет его документом (/mydata).
+func (h *Handler) MyDataHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.Message.From.ID
+
+	exists, err := h.userRepo.UserExists(userID)
+	if err != nil {
+		fmt.Println("Error checking user existence:", err)
+		return
+	}
+	if !exists {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Бот сіз туралы ешқандай дерек сақтамайды.",
+		})
+		return
+	}
+
+	cooldown := time.Duration(h.config.DataExportCooldownHours) * time.Hour
+	ok, left, err := h.chatState.TryStartExport(ctx, userID, cooldown)
+	if err != nil {
+		fmt.Println("Ошибка при проверке лимита выгрузки:", err)
+		return
+	}
+	if !ok {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   fmt.Sprintf("Деректерді жиі жүктеуге болмайды. Қайта көріңіз: %d сағаттан кейін.", int(left.Hours())+1),
+		})
+		return
+	}
+
+	archive, err := h.buildDataExport(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при выгрузке данных пользователя:", err)
+		// Неудачная попытка не должна отнимать у пользователя право на выгрузку.
+		h.cancelExport(ctx, userID)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Деректерді жинау кезінде қате пайда болды, кейінірек қайталап көріңіз.",
+		})
+		return
+	}
+
+	if _, err := b.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID:         userID,
+		Document:       &models.InputFileUpload{Filename: fmt.Sprintf("tanysu-%d.zip", userID), Data: bytes.NewReader(archive)},
+		Caption:        "Бот сіз туралы сақтайтын барлық деректер.",
+		ProtectContent: true,
+	}); err != nil {
+		fmt.Println("Ошибка при отправке выгрузки:", err)
+		h.cancelExport(ctx, userID)
+	}
+}
+
+func (h *Handler) cancelExport(ctx context.Context, userID int64) {
+	if err := h.chatState.CancelExport(ctx, userID); err != nil {
+		fmt.Println("Ошибка при снятии лимита выгрузки:", err)
+	}
+}
+
+// buildDataExport собирает данные пользователя из SQLite, Redis и хранилища аватаров.
+func (h *Handler) buildDataExport(ctx context.Context, userID int64) ([]byte, error) {
+	user, err := h.userRepo.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	active, err := h.userRepo.IsUserActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := h.chatState.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	last, err := h.chatState.GetLastSession(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := h.chatState.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	votes, err := h.chatState.GetUserPollVotes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &dataExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: exportProfile{
+			UserID:       user.UserID,
+			UserName:     user.UserName,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+			Nickname:     user.UserNickname,
+			Sex:          user.UserSex,
+			Age:          user.UserAge,
+			Geo:          user.UserGeo,
+			City:         user.UserCity,
+			GeoApprox:    user.GeoApprox,
+			Bio:          user.Bio,
+			Contact:      user.Contact,
+			AvatarFileID: user.AvaFileID,
+			LanguageCode: user.LanguageCode,
+			BlockedBot:   !active,
+		},
+		Settings:  settings,
+		Sessions:  exportSessions{Current: current, Last: last},
+		PollVotes: votes,
+	}
+	for _, id := range users {
+		if id == userID {
+			export.Sessions.InQueue = true
+		}
+	}
+
+	avatar, err := h.avatars.Load(ctx, userID)
+	if errors.Is(err, os.ErrNotExist) {
+		avatar = nil
+	} else if err != nil {
+		return nil, err
+	}
+	return buildExportArchive(export, avatar)
+}
+
+// buildExportArchive упаковывает data.json и аватар (если есть) в ZIP.
+func buildExportArchive(export *dataExport, avatar []byte) ([]byte, error) {
+	if avatar != nil {
+		export.Avatar = exportAvatarName
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal export: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := addZipFile(zw, "data.json", data); err != nil {
+		return nil, err
+	}
+	if avatar != nil {
+		if err := addZipFile(zw, exportAvatarName, avatar); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish export: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func addZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	return nil
+}
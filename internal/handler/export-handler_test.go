@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestBuildExportArchive(t *testing.T) {
+	export := &dataExport{
+		Profile:  exportProfile{UserID: 123, Nickname: "tanysu"},
+		Settings: map[string]bool{"echo": true},
+		Sessions: exportSessions{Current: "session"},
+	}
+	archive, err := buildExportArchive(export, []byte("jpeg"))
+	assert.NoError(t, err)
+
+	files := readZip(t, archive)
+	assert.Equal(t, []byte("jpeg"), files[exportAvatarName])
+
+	var decoded dataExport
+	assert.NoError(t, json.Unmarshal(files["data.json"], &decoded))
+	assert.Equal(t, int64(123), decoded.Profile.UserID)
+	assert.Equal(t, "tanysu", decoded.Profile.Nickname)
+	assert.True(t, decoded.Settings["echo"])
+	assert.Equal(t, "session", decoded.Sessions.Current)
+	assert.Equal(t, exportAvatarName, decoded.Avatar)
+}
+
+func TestBuildExportArchive_NoAvatar(t *testing.T) {
+	archive, err := buildExportArchive(&dataExport{Profile: exportProfile{UserID: 123}}, nil)
+	assert.NoError(t, err)
+
+	files := readZip(t, archive)
+	assert.Len(t, files, 1)
+	assert.NotContains(t, string(files["data.json"]), `"avatar"`)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// fanOut запускает независимые отправки параллельно и собирает их ошибки.
+type fanOut struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// Go запускает fn; name попадает в текст ошибки, чтобы было видно, какая отправка не удалась.
+func (f *fanOut) Go(name string, fn func() error) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		if err := fn(); err != nil {
+			f.mu.Lock()
+			f.errs = append(f.errs, fmt.Errorf("%s: %w", name, err))
+			f.mu.Unlock()
+		}
+	}()
+}
+
+// Wait ждёт все отправки и возвращает их ошибки, объединённые errors.Join.
+func (f *fanOut) Wait() error {
+	f.wg.Wait()
+	return errors.Join(f.errs...)
+}
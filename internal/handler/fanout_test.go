@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanOut_JoinsErrors(t *testing.T) {
+	errPartner := errors.New("partner down")
+
+	var f fanOut
+	f.Go("собеседнику", func() error { return errPartner })
+	f.Go("отправителю", func() error { return nil })
+	f.Go("канал", func() error { return errors.New("channel down") })
+
+	err := f.Wait()
+	assert.ErrorIs(t, err, errPartner)
+	assert.Contains(t, err.Error(), "собеседнику: partner down")
+	assert.Contains(t, err.Error(), "канал: channel down")
+	assert.NotContains(t, err.Error(), "отправителю")
+}
+
+func TestFanOut_NoErrors(t *testing.T) {
+	var f fanOut
+	f.Go("собеседнику", func() error { return nil })
+	assert.NoError(t, f.Wait())
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"tanysu-bot/internal/geo"
+	"tanysu-bot/internal/keyboard"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// locationConfirmTTL — сколько ждём выбора точности геолокации.
+const locationConfirmTTL = 10 * time.Minute
+
+// maxHorizontalAccuracy — наибольшая погрешность, которую Telegram принимает в sendLocation, в метрах.
+const maxHorizontalAccuracy = 1500
+
+// Варианты ответа на вопрос о точности геолокации.
+const (
+	locationExact  = "exact"
+	locationApprox = "approx"
+	locationCancel = "cancel"
+)
+
+// locationPayload — геолокация, ожидающая решения отправителя, и выбранный им вариант.
+type locationPayload struct {
+	SenderID  int64  `json:"sender_id"`
+	SessionID string `json:"session_id"`
+	Message   []byte `json:"message"`
+	Choice    string `json:"choice"`
+}
+
+// needsLocationConfirm сообщает, что перед пересылкой геолокации нужно спросить отправителя.
+// Место (venue) — это адрес, а не положение пользователя, поэтому оно пересылается сразу.
+func (h *Handler) needsLocationConfirm(msg *models.Message) bool {
+	return h.config.ConfirmExactLocation && h.config.GeoPrecisionKm > 0 && msg.Location != nil && msg.Venue == nil
+}
+
+// approximateLocation возвращает копию сообщения, где геолокация огрублена до precisionKm.
+// Live-локация превращается в обычную: её обновления выдали бы точное положение.
+func approximateLocation(msg *models.Message, precisionKm float64) *models.Message {
+	approx := *msg
+	location := *msg.Location
+	location.Latitude, location.Longitude = geo.Fuzz(location.Latitude, location.Longitude, precisionKm)
+	location.HorizontalAccuracy = math.Min(precisionKm*1000, maxHorizontalAccuracy)
+	location.LivePeriod = 0
+	location.Heading = 0
+	location.ProximityAlertRadius = 0
+	approx.Location = &location
+	return &approx
+}
+
+// askLocationConfirm спрашивает отправителя, переслать собеседнику точную геолокацию или примерную.
+func (h *Handler) askLocationConfirm(ctx context.Context, b *bot.Bot, msg *models.Message, sessionID string) {
+	data, err := encodeMessage(msg)
+	if err != nil {
+		fmt.Println("Ошибка при сохранении геолокации:", err)
+		return
+	}
+
+	kb := keyboard.NewKeyboard()
+	var row []models.InlineKeyboardButton
+	for _, option := range []struct{ text, choice string }{
+		{"📍 Дәл орын", locationExact},
+		{"🎯 Шамамен", locationApprox},
+		{"✖️ Болдырмау", locationCancel},
+	} {
+		button, err := h.locationCallback.Button(ctx, option.text, locationPayload{
+			SenderID:  msg.From.ID,
+			SessionID: sessionID,
+			Message:   data,
+			Choice:    option.choice,
+		})
+		if err != nil {
+			fmt.Println("Ошибка при создании кнопки геолокации:", err)
+			return
+		}
+		row = append(row, button)
+	}
+	kb.AddRow(row...)
+
+	precision := strconv.FormatFloat(h.config.GeoPrecisionKm, 'f', -1, 64)
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:          msg.Chat.ID,
+		Text:            fmt.Sprintf("Сөйлесушіге дәл орныңызды жібересіз бе? \"🎯 Шамамен\" таңдалса, орын %s км дәлдікпен жіберіледі.", precision),
+		ReplyParameters: &models.ReplyParameters{MessageID: msg.ID, AllowSendingWithoutReply: true},
+		ReplyMarkup:     kb.Build(),
+		ProtectContent:  true,
+	})
+	if err != nil {
+		fmt.Println("Ошибка при отправке вопроса о геолокации:", err)
+	}
+}
+
+// LocationConfirmHandler пересылает геолокацию с выбранной отправителем точностью.
+func (h *Handler) LocationConfirmHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.CallbackQuery.From.ID
+
+	payload, err := h.locationCallback.Decode(ctx, update.CallbackQuery.Data)
+	if errors.Is(err, keyboard.ErrCallbackExpired) {
+		h.answerCallbackAlert(ctx, b, update, "Бұл сұрақтың мерзімі өтті. Геолокацияны қайта жіберіңіз.")
+		return
+	}
+	if err != nil {
+		fmt.Println("Ошибка при чтении выбора геолокации:", err)
+		return
+	}
+
+	sessionID, err := h.chatState.GetSession(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
+		return
+	}
+	if payload.SenderID != userID || sessionID == "" || sessionID != payload.SessionID {
+		h.answerCallbackAlert(ctx, b, update, "Чат аяқталды, геолокация жіберілмеді.")
+		return
+	}
+
+	// Вопрос больше не нужен: остальные кнопки под ним тоже теряют смысл.
+	if question := update.CallbackQuery.Message.Message; question != nil {
+		b.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    question.Chat.ID,
+			MessageID: question.ID,
+		})
+	}
+	answer := &bot.AnswerCallbackQueryParams{CallbackQueryID: update.CallbackQuery.ID}
+	if payload.Choice == locationCancel {
+		answer.Text = "Геолокация жіберілмеді."
+	}
+	b.AnswerCallbackQuery(ctx, answer)
+	if payload.Choice == locationCancel {
+		return
+	}
+
+	msg, err := decodeMessage(payload.Message)
+	if err != nil {
+		fmt.Println("Ошибка при чтении геолокации:", err)
+		return
+	}
+	if payload.Choice == locationApprox {
+		msg = approximateLocation(msg, h.config.GeoPrecisionKm)
+	}
+
+	partnerID, err := h.chatState.GetUserPartner(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении собеседника:", err)
+		return
+	}
+	if partnerID == 0 {
+		return
+	}
+	h.relay(ctx, b, msg, partnerID, sessionID)
+}
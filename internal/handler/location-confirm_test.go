@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"tanysu-bot/internal/geo"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApproximateLocation(t *testing.T) {
+	msg := &models.Message{
+		ID: 7,
+		Location: &models.Location{
+			Latitude:             43.25667,
+			Longitude:            76.92861,
+			HorizontalAccuracy:   5,
+			LivePeriod:           900,
+			Heading:              90,
+			ProximityAlertRadius: 100,
+		},
+	}
+
+	approx := approximateLocation(msg, 1)
+	assert.Equal(t, 7, approx.ID)
+	assert.LessOrEqual(t, geo.Distance(43.25667, 76.92861, approx.Location.Latitude, approx.Location.Longitude), 0.75)
+	assert.Equal(t, 1000.0, approx.Location.HorizontalAccuracy)
+	assert.Zero(t, approx.Location.LivePeriod)
+	assert.Zero(t, approx.Location.Heading)
+	assert.Zero(t, approx.Location.ProximityAlertRadius)
+
+	// Исходное сообщение не меняется: его точная версия нужна для варианта "Дәл орын".
+	assert.Equal(t, 43.25667, msg.Location.Latitude)
+	assert.Equal(t, 900, msg.Location.LivePeriod)
+
+	assert.Equal(t, float64(maxHorizontalAccuracy), approximateLocation(msg, 5).Location.HorizontalAccuracy)
+}
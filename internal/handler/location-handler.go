@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"tanysu-bot/internal/repository"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// liveLocationMaxTTL — сколько помним связь для live-локации, в том числе "бессрочной".
+const liveLocationMaxTTL = 7 * 24 * time.Hour
+
+// rememberLiveLocation связывает live-локацию отправителя с копией у собеседника,
+// чтобы потом зеркалить её обновления, пока идёт сессия sessionID.
+func (h *Handler) rememberLiveLocation(ctx context.Context, msg, partnerMsg *models.Message, sessionID string) {
+	if msg.Location == nil || msg.Location.LivePeriod == 0 {
+		return
+	}
+	ttl := min(time.Duration(msg.Location.LivePeriod)*time.Second, liveLocationMaxTTL)
+	live := repository.LiveLocation{
+		TrackedMessage: repository.TrackedMessage{ChatID: partnerMsg.Chat.ID, MessageID: partnerMsg.ID},
+		SessionID:      sessionID,
+	}
+	if err := h.chatState.SetLiveLocation(ctx, msg.Chat.ID, msg.ID, live, ttl); err != nil {
+		fmt.Println("Ошибка при сохранении live-локации:", err)
+	}
+}
+
+// LiveLocationHandler зеркалит собеседнику обновления live-локации, которые приходят как edited_message.
+func (h *Handler) LiveLocationHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	msg := update.EditedMessage
+	partnerCopy, err := h.chatState.GetLiveLocation(ctx, msg.Chat.ID, msg.ID)
+	if err != nil {
+		fmt.Println("Ошибка при получении live-локации:", err)
+		return
+	}
+	if partnerCopy == nil {
+		return
+	}
+
+	// Трансляция остановлена или чат, в котором она началась, уже закончился — останавливаем копию.
+	sessionID, err := h.chatState.GetSession(ctx, msg.Chat.ID)
+	if err != nil {
+		fmt.Println("Ошибка при получении сессии:", err)
+		return
+	}
+	if msg.Location.LivePeriod == 0 || sessionID != partnerCopy.SessionID {
+		h.stopLiveLocation(ctx, b, partnerCopy)
+		if err := h.chatState.RemoveLiveLocation(ctx, msg.Chat.ID, msg.ID); err != nil {
+			fmt.Println("Ошибка при удалении live-локации:", err)
+		}
+		return
+	}
+
+	fmt.Printf("LIVE_LOCATION | Chat=%d | Lat=%.5f | Long=%.5f\n", msg.Chat.ID, msg.Location.Latitude, msg.Location.Longitude)
+	if _, err := b.EditMessageLiveLocation(ctx, &bot.EditMessageLiveLocationParams{
+		ChatID:               partnerCopy.ChatID,
+		MessageID:            partnerCopy.MessageID,
+		Latitude:             msg.Location.Latitude,
+		Longitude:            msg.Location.Longitude,
+		HorizontalAccuracy:   msg.Location.HorizontalAccuracy,
+		Heading:              msg.Location.Heading,
+		ProximityAlertRadius: msg.Location.ProximityAlertRadius,
+	}); err != nil {
+		fmt.Println("Ошибка при обновлении live-локации:", err)
+	}
+}
+
+// stopLiveLocation останавливает копию live-локации у собеседника.
+func (h *Handler) stopLiveLocation(ctx context.Context, b *bot.Bot, partnerCopy *repository.LiveLocation) {
+	if _, err := b.StopMessageLiveLocation(ctx, &bot.StopMessageLiveLocationParams{
+		ChatID:    partnerCopy.ChatID,
+		MessageID: partnerCopy.MessageID,
+	}); err != nil {
+		fmt.Println("Ошибка при остановке live-локации:", err)
+	}
+}
+
+// stopLiveLocations останавливает у собеседника копии всех live-локаций пользователя
+// и забывает их. Вызывается, когда чат заканчивается.
+func (h *Handler) stopLiveLocations(ctx context.Context, b *bot.Bot, userID int64) {
+	locations, err := h.chatState.TakeLiveLocations(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении live-локаций:", err)
+	}
+	for i := range locations {
+		h.stopLiveLocation(ctx, b, &locations[i])
+	}
+}
+
+// IsLiveLocationUpdate отбирает обновления live-локации для LiveLocationHandler.
+func (h *Handler) IsLiveLocationUpdate(update *models.Update) bool {
+	return update.EditedMessage != nil && update.EditedMessage.Location != nil
+}
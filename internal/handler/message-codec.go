@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// storedMessage — models.Message в виде, пригодном для хранения. У models.PaidMedia нет MarshalJSON,
+// и после json.Marshal его не прочитать обратно, поэтому элементы платного медиа хранятся
+// отдельно в формате Telegram.
+type storedMessage struct {
+	Message   *models.Message   `json:"message"`
+	PaidMedia []json.RawMessage `json:"paid_media,omitempty"`
+}
+
+// encodeMessage сериализует сообщение для повторной отправки.
+func encodeMessage(msg *models.Message) ([]byte, error) {
+	stored := storedMessage{Message: msg}
+	if msg.PaidMedia != nil {
+		message := *msg
+		paid := *msg.PaidMedia
+		paid.PaidMedia = nil
+		message.PaidMedia = &paid
+		stored.Message = &message
+
+		for _, item := range msg.PaidMedia.PaidMedia {
+			var variant any
+			switch {
+			case item.Photo != nil:
+				photo := *item.Photo
+				photo.Type = models.PaidMediaTypePhoto
+				variant = photo
+			case item.Video != nil:
+				video := *item.Video
+				video.Type = models.PaidMediaTypeVideo
+				variant = video
+			case item.Preview != nil:
+				preview := *item.Preview
+				preview.Type = models.PaidMediaTypePreview
+				variant = preview
+			default:
+				continue
+			}
+			raw, err := json.Marshal(variant)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal paid media: %w", err)
+			}
+			stored.PaidMedia = append(stored.PaidMedia, raw)
+		}
+	}
+	return json.Marshal(stored)
+}
+
+// decodeMessage восстанавливает сообщение, сохранённое encodeMessage.
+func decodeMessage(data []byte) (*models.Message, error) {
+	var stored storedMessage
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	if stored.Message == nil {
+		return nil, fmt.Errorf("stored message is empty")
+	}
+	for _, raw := range stored.PaidMedia {
+		var item models.PaidMedia
+		if err := item.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal paid media: %w", err)
+		}
+		stored.Message.PaidMedia.PaidMedia = append(stored.Message.PaidMedia.PaidMedia, item)
+	}
+	return stored.Message, nil
+}
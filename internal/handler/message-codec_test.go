@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageCodec_RoundTrip(t *testing.T) {
+	msg := &models.Message{
+		ID:      10,
+		From:    &models.User{ID: 123, Username: "user"},
+		Chat:    models.Chat{ID: 123},
+		Caption: "caption",
+		Photo:   []models.PhotoSize{{FileID: "small"}, {FileID: "large"}},
+	}
+
+	data, err := encodeMessage(msg)
+	assert.NoError(t, err)
+	decoded, err := decodeMessage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.ID, decoded.ID)
+	assert.Equal(t, msg.From, decoded.From)
+	assert.Equal(t, msg.Chat, decoded.Chat)
+	assert.Equal(t, msg.Caption, decoded.Caption)
+	assert.Equal(t, msg.Photo, decoded.Photo)
+}
+
+func TestMessageCodec_PaidMedia(t *testing.T) {
+	msg := &models.Message{
+		ID:   11,
+		From: &models.User{ID: 123},
+		Chat: models.Chat{ID: 123},
+		PaidMedia: &models.PaidMediaInfo{
+			StarCount: 5,
+			PaidMedia: []models.PaidMedia{
+				{Type: models.PaidMediaTypePhoto, Photo: &models.PaidMediaPhoto{Photo: []models.PhotoSize{{FileID: "photo"}}}},
+				{Type: models.PaidMediaTypeVideo, Video: &models.PaidMediaVideo{Video: models.Video{FileID: "video"}}},
+			},
+		},
+	}
+
+	data, err := encodeMessage(msg)
+	assert.NoError(t, err)
+	decoded, err := decodeMessage(data)
+	assert.NoError(t, err)
+
+	// Исходное сообщение не должно меняться при сериализации.
+	assert.Len(t, msg.PaidMedia.PaidMedia, 2)
+
+	assert.Equal(t, 5, decoded.PaidMedia.StarCount)
+	if assert.Len(t, decoded.PaidMedia.PaidMedia, 2) {
+		assert.Equal(t, "photo", decoded.PaidMedia.PaidMedia[0].Photo.Photo[0].FileID)
+		assert.Equal(t, "video", decoded.PaidMedia.PaidMedia[1].Video.Video.FileID)
+	}
+}
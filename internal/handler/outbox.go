@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tanysu-bot/internal/repository"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	// outboxInterval — как часто проверяем сессии, которым пора повторить доставку.
+	outboxInterval = time.Second
+	outboxBatch    = 100
+
+	// Задержка между попытками растёт вдвое от outboxBaseDelay до outboxMaxDelay.
+	outboxBaseDelay = 2 * time.Second
+	outboxMaxDelay  = 5 * time.Minute
+)
+
+// isTransient проверяет, что отправка может получиться позже: сеть, 5xx или 429.
+// Ответы 4xx означают, что повтор ничего не изменит.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if bot.IsTooManyRequestsError(err) {
+		return true
+	}
+	for _, permanent := range []error{bot.ErrorForbidden, bot.ErrorBadRequest, bot.ErrorUnauthorized, bot.ErrorNotFound, bot.ErrorConflict} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	return !bot.IsMigrateError(err)
+}
+
+// outboxBackoff возвращает задержку перед следующей попыткой после attempts неудачных.
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBaseDelay
+	for i := 1; i < attempts && delay < outboxMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxDelay)
+}
+
+// queueForDelivery кладёт сообщение в очередь доставки сессии и сообщает об этом отправителю.
+// attempts — сколько попыток уже сделано.
+func (h *Handler) queueForDelivery(ctx context.Context, b *bot.Bot, msg *models.Message, partnerID int64, sessionID string, attempts int) {
+	data, err := encodeMessage(msg)
+	if err == nil {
+		err = h.chatState.PushOutbox(ctx, sessionID, &repository.OutboxEntry{
+			SenderID:  msg.From.ID,
+			PartnerID: partnerID,
+			Attempts:  attempts,
+			Message:   data,
+		})
+	}
+	if err != nil {
+		fmt.Println("Ошибка при постановке сообщения в очередь доставки:", err)
+		h.trackMessages(ctx, sessionID, h.sendDeliveryFailed(ctx, b, msg, sessionID))
+		return
+	}
+
+	notice, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:              msg.Chat.ID,
+		Text:                "⏳ Хабарлама кезекке қойылды, байланыс қалпына келгенде жеткіземіз.",
+		ReplyParameters:     &models.ReplyParameters{MessageID: msg.ID, AllowSendingWithoutReply: true},
+		DisableNotification: true,
+		ProtectContent:      true,
+	})
+	if err != nil {
+		fmt.Println("Ошибка при отправке уведомления об очереди:", err)
+		return
+	}
+	h.trackMessages(ctx, sessionID, notice)
+}
+
+// RunOutbox повторяет доставку сообщений из очередей сессий. Очереди хранятся в Redis,
+// поэтому после перезапуска бота доставка продолжается.
+func (h *Handler) RunOutbox(ctx context.Context, b *bot.Bot) {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sessions, err := h.chatState.DueOutboxSessions(ctx, time.Now(), outboxBatch)
+			if err != nil {
+				fmt.Println("Ошибка при чтении очереди доставки:", err)
+				continue
+			}
+			for _, sessionID := range sessions {
+				h.flushOutbox(ctx, b, sessionID)
+			}
+		}
+	}
+}
+
+// flushOutbox доставляет сообщения сессии по порядку и останавливается на первой временной ошибке,
+// чтобы следующие сообщения не обогнали её.
+func (h *Handler) flushOutbox(ctx context.Context, b *bot.Bot, sessionID string) {
+	for {
+		entry, err := h.chatState.PeekOutbox(ctx, sessionID)
+		if err != nil {
+			fmt.Println("Ошибка при чтении очереди доставки:", err)
+			return
+		}
+		if entry == nil {
+			if err := h.chatState.FinishOutbox(ctx, sessionID); err != nil {
+				fmt.Println("Ошибка при завершении очереди доставки:", err)
+			}
+			return
+		}
+
+		msg, err := decodeMessage(entry.Message)
+		if err != nil {
+			fmt.Println("Ошибка при чтении сообщения из очереди:", err)
+			h.popOutbox(ctx, sessionID)
+			continue
+		}
+
+		// Сессия уже закончилась — доставлять некому.
+		current, err := h.chatState.GetSession(ctx, entry.SenderID)
+		if err != nil {
+			fmt.Println("Ошибка при получении сессии:", err)
+			return
+		}
+		if current != sessionID {
+			h.popOutbox(ctx, sessionID)
+			h.sendChatEndedUndelivered(ctx, b, msg)
+			continue
+		}
+
+		err = h.deliver(ctx, b, msg, entry.PartnerID, sessionID)
+		switch {
+		case err == nil:
+			h.popOutbox(ctx, sessionID)
+		case isChatGone(err):
+			if err := h.chatState.ClearOutbox(ctx, sessionID); err != nil {
+				fmt.Println("Ошибка при очистке очереди доставки:", err)
+			}
+			h.endDeadChat(ctx, b, entry.PartnerID, entry.SenderID)
+			return
+		case isTransient(err) && entry.Attempts+1 < h.config.OutboxMaxAttempts:
+			entry.Attempts++
+			nextAt := time.Now().Add(outboxBackoff(entry.Attempts))
+			if err := h.chatState.RetryOutboxLater(ctx, sessionID, entry, nextAt); err != nil {
+				fmt.Println("Ошибка при переносе доставки:", err)
+			}
+			return
+		default:
+			// Попытки кончились или ошибка постоянная — сообщаем отправителю.
+			fmt.Printf("OUTBOX | Session=%s | сообщение брошено после %d попыток: %v\n", sessionID, entry.Attempts+1, err)
+			h.popOutbox(ctx, sessionID)
+			h.trackMessages(ctx, sessionID, h.sendDeliveryFailed(ctx, b, msg, sessionID))
+		}
+	}
+}
+
+func (h *Handler) popOutbox(ctx context.Context, sessionID string) {
+	if err := h.chatState.PopOutbox(ctx, sessionID); err != nil {
+		fmt.Println("Ошибка при удалении сообщения из очереди:", err)
+	}
+}
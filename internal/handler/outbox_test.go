@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, isTransient(errors.New("error do request for method sendMessage, connection reset")))
+	assert.True(t, isTransient(errors.New("error response from telegram for method sendMessage, 502 Bad Gateway")))
+	assert.True(t, isTransient(&bot.TooManyRequestsError{Message: "too many requests", RetryAfter: 5}))
+
+	assert.False(t, isTransient(nil))
+	assert.False(t, isTransient(fmt.Errorf("%w, bot was blocked by the user", bot.ErrorForbidden)))
+	assert.False(t, isTransient(fmt.Errorf("%w, message is too long", bot.ErrorBadRequest)))
+	assert.False(t, isTransient(context.Canceled))
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, outboxBackoff(1))
+	assert.Equal(t, 4*time.Second, outboxBackoff(2))
+	assert.Equal(t, 8*time.Second, outboxBackoff(3))
+	assert.Equal(t, outboxMaxDelay, outboxBackoff(20))
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tanysu-bot/internal/repository"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// linkPoll связывает копии опроса у обоих собеседников, чтобы голоса считались вместе.
+// Telegram сообщает о голосах только в опросах, отправленных ботом, поэтому отправитель
+// получает собственную копию (в режиме эха она уже есть — senderCopy).
+// Возвращает новые сообщения, которые нужно учесть для "сжигания".
+func (h *Handler) linkPoll(ctx context.Context, b *bot.Bot, msg, partnerMsg, senderCopy *models.Message, content *relayContent) []*models.Message {
+	var sent []*models.Message
+	if senderCopy == nil {
+		var err error
+		senderCopy, err = h.outbound.Send(ctx, msg.Chat.ID, func(ctx context.Context) (*models.Message, error) {
+			return content.copyTo(ctx, b, msg.Chat.ID, nil)
+		})
+		if err != nil {
+			fmt.Println("Ошибка при отправке копии опроса отправителю:", err)
+			return nil
+		}
+		sent = append(sent, senderCopy)
+	}
+
+	group := &repository.PollGroup{Anonymous: msg.Poll.IsAnonymous}
+	for _, o := range msg.Poll.Options {
+		group.Options = append(group.Options, o.Text)
+	}
+	for _, copyMsg := range []*models.Message{partnerMsg, senderCopy} {
+		if copyMsg.Poll == nil {
+			continue
+		}
+		result, err := h.outbound.Send(ctx, copyMsg.Chat.ID, func(ctx context.Context) (*models.Message, error) {
+			return b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:              copyMsg.Chat.ID,
+				Text:                renderPollTally(group, make([]int, len(group.Options))),
+				ReplyParameters:     &models.ReplyParameters{MessageID: copyMsg.ID, AllowSendingWithoutReply: true},
+				DisableNotification: true,
+				ProtectContent:      true,
+			})
+		})
+		if err != nil {
+			fmt.Println("Ошибка при отправке итогов опроса:", err)
+			continue
+		}
+		sent = append(sent, result)
+		group.Copies = append(group.Copies, repository.PollCopy{
+			ChatID:      copyMsg.Chat.ID,
+			MessageID:   copyMsg.ID,
+			PollID:      copyMsg.Poll.ID,
+			ResultMsgID: result.ID,
+		})
+	}
+
+	if err := h.chatState.SavePollGroup(ctx, group); err != nil {
+		fmt.Println("Ошибка при сохранении опроса:", err)
+	}
+	return sent
+}
+
+// PollUpdateHandler обновляет общие итоги опроса: голоса открытых опросов приходят как poll_answer,
+// счётчики анонимных — как обновления poll.
+func (h *Handler) PollUpdateHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	var pollID string
+	if update.PollAnswer != nil {
+		pollID = update.PollAnswer.PollID
+	} else {
+		pollID = update.Poll.ID
+	}
+
+	group, err := h.chatState.GetPollGroupByPoll(ctx, pollID)
+	if err != nil {
+		fmt.Println("Ошибка при получении опроса:", err)
+		return
+	}
+	if group == nil {
+		return
+	}
+
+	switch {
+	case update.PollAnswer != nil && update.PollAnswer.User != nil && !group.Anonymous:
+		answer := update.PollAnswer
+		if err := h.chatState.SetPollVote(ctx, group.ID, answer.User.ID, answer.OptionIDs); err != nil {
+			fmt.Println("Ошибка при сохранении голоса:", err)
+			return
+		}
+	case update.Poll != nil && group.Anonymous:
+		counts := make([]int, len(update.Poll.Options))
+		for i, o := range update.Poll.Options {
+			counts[i] = o.VoterCount
+		}
+		if err := h.chatState.SetPollCounts(ctx, group.ID, pollID, counts); err != nil {
+			fmt.Println("Ошибка при сохранении счётчиков опроса:", err)
+			return
+		}
+	default:
+		return
+	}
+
+	tally, err := h.chatState.PollTally(ctx, group)
+	if err != nil {
+		fmt.Println("Ошибка при подсчёте голосов:", err)
+		return
+	}
+	text := renderPollTally(group, tally)
+	for _, c := range group.Copies {
+		if _, err := b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:    c.ChatID,
+			MessageID: c.ResultMsgID,
+			Text:      text,
+		}); err != nil {
+			fmt.Println("Ошибка при обновлении итогов опроса:", err)
+		}
+	}
+}
+
+// IsPollUpdate отбирает голоса и изменения опросов для PollUpdateHandler.
+func (h *Handler) IsPollUpdate(update *models.Update) bool {
+	return update.PollAnswer != nil || update.Poll != nil
+}
+
+// renderPollTally выводит общие итоги по обеим копиям опроса.
+func renderPollTally(group *repository.PollGroup, tally []int) string {
+	var sb strings.Builder
+	sb.WriteString("📊 Ортақ нәтиже (екі жақтың дауыстары):")
+	total := 0
+	for i, option := range group.Options {
+		count := 0
+		if i < len(tally) {
+			count = tally[i]
+		}
+		total += count
+		fmt.Fprintf(&sb, "\n• %s — %d", option, count)
+	}
+	fmt.Fprintf(&sb, "\nБарлығы: %d", total)
+	return sb.String()
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tanysu-bot/internal/keyboard"
+	"tanysu-bot/internal/repository"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// bioMaxLen — ограничение на длину "Өзі туралы".
+const bioMaxLen = 300
+
+const (
+	profileCancelText = "✖️ Болдырмау"
+	bioClearText      = "🗑 Тазалау"
+)
+
+// bioField — поле "Өзі туралы". При регистрации оно не спрашивается и меняется только через /edit.
+var bioField = registrationStep{
+	name:    "bio",
+	label:   "Өзі туралы",
+	prompt:  fmt.Sprintf("Өзіңіз туралы қысқаша жазыңыз (%d таңбаға дейін).", bioMaxLen),
+	buttons: []models.KeyboardButton{keyboard.NewReplyButton(bioClearText)},
+	filled:  func(user *repository.User) bool { return user.Bio != "" },
+	apply: func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) (string, error) {
+		bio := strings.TrimSpace(msg.Text)
+		if bio == bioClearText {
+			bio = ""
+		} else if bio == "" || utf8.RuneCountInString(bio) > bioMaxLen {
+			return fmt.Sprintf("Мәтін жіберіңіз, %d таңбадан аспауы керек.", bioMaxLen), nil
+		}
+		return "", h.userRepo.UpdateUserBio(msg.From.ID, bio)
+	},
+}
+
+// profileFields — все поля анкеты, которые можно изменить через /edit.
+var profileFields = append(registrationSteps[:len(registrationSteps):len(registrationSteps)], bioField)
+
+// profileField ищет поле анкеты по названию.
+func profileField(name string) (registrationStep, bool) {
+	for _, step := range profileFields {
+		if step.name == name {
+			return step, true
+		}
+	}
+	return registrationStep{}, false
+}
+
+// profileComplete проверяет, заполнены ли обязательные поля анкеты.
+func profileComplete(user *repository.User) bool {
+	return user.AvaFileID != "" && user.UserNickname != "" && user.UserSex != "" && user.UserAge != 0 && user.UserGeo != ""
+}
+
+// renderProfile формирует текст карточки пользователя.
+func renderProfile(user *repository.User) string {
+	location := "көрсетілмеген"
+	if user.UserCity != "" {
+		location = user.UserCity
+		if user.GeoApprox {
+			location += " (шамамен)"
+		}
+	} else if user.UserGeo != "" {
+		location = "бөлісілген"
+	}
+	text := fmt.Sprintf("👤 @%s\nЖынысы: %s\nЖасы: %d\nОрны: %s", user.UserNickname, user.UserSex, user.UserAge, location)
+	if user.Bio != "" {
+		text += "\nӨзі туралы: " + user.Bio
+	}
+	return text
+}
+
+// sendProfileCard отправляет карточку пользователя: аватар с подписью или текст, если аватара нет.
+func (h *Handler) sendProfileCard(ctx context.Context, b *bot.Bot, chatID int64, user *repository.User, markup models.ReplyMarkup) {
+	var err error
+	if user.AvaFileID != "" {
+		_, err = b.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID:         chatID,
+			Photo:          &models.InputFileString{Data: user.AvaFileID},
+			Caption:        renderProfile(user),
+			ReplyMarkup:    markup,
+			ProtectContent: true,
+		})
+	} else {
+		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:         chatID,
+			Text:           renderProfile(user),
+			ReplyMarkup:    markup,
+			ProtectContent: true,
+		})
+	}
+	if err != nil {
+		fmt.Println("Ошибка при отправке анкеты:", err)
+	}
+}
+
+// editButtonKeyboard — кнопка перехода к /edit под карточкой.
+func editButtonKeyboard() *models.InlineKeyboardMarkup {
+	kb := keyboard.NewKeyboard()
+	kb.AddRow(keyboard.NewInlineButton("✏️ Өңдеу", "edit"))
+	return kb.Build()
+}
+
+// registeredUser возвращает анкету пользователя или сообщает ему, что регистрация не завершена.
+func (h *Handler) registeredUser(ctx context.Context, b *bot.Bot, userID int64) (*repository.User, bool) {
+	user, err := h.userRepo.GetUser(userID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return nil, false
+	}
+	if !profileComplete(user) {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Анкетаңыз әлі толтырылмаған. Тіркеуді жалғастыру үшін кез келген хабарлама жіберіңіз.",
+		})
+		return nil, false
+	}
+	return user, true
+}
+
+// ProfileHandler показывает пользователю его анкету (/profile).
+func (h *Handler) ProfileHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	h.ensureUserInDB(update)
+
+	userID := update.Message.From.ID
+	user, ok := h.registeredUser(ctx, b, userID)
+	if !ok {
+		return
+	}
+	h.sendProfileCard(ctx, b, userID, user, editButtonKeyboard())
+}
+
+// EditHandler показывает поля анкеты, которые можно изменить (/edit или кнопка "✏️ Өңдеу").
+func (h *Handler) EditHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	h.ensureUserInDB(update)
+
+	var userID int64
+	if update.Message != nil {
+		userID = update.Message.From.ID
+	} else {
+		userID = update.CallbackQuery.From.ID
+		b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: update.CallbackQuery.ID,
+		})
+	}
+	if _, ok := h.registeredUser(ctx, b, userID); !ok {
+		return
+	}
+
+	kb := keyboard.NewKeyboard()
+	for i := 0; i < len(profileFields); i += 2 {
+		row := []models.InlineKeyboardButton{keyboard.NewInlineButton(profileFields[i].label, "edit_"+profileFields[i].name)}
+		if i+1 < len(profileFields) {
+			row = append(row, keyboard.NewInlineButton(profileFields[i+1].label, "edit_"+profileFields[i+1].name))
+		}
+		kb.AddRow(row...)
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        "Қай мәліметті өзгерткіңіз келеді?",
+		ReplyMarkup: kb.Build(),
+	})
+}
+
+// EditFieldHandler запоминает выбранное поле и спрашивает его новое значение.
+func (h *Handler) EditFieldHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := update.CallbackQuery.From.ID
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: update.CallbackQuery.ID,
+	})
+
+	field, ok := profileField(strings.TrimPrefix(update.CallbackQuery.Data, "edit_"))
+	if !ok {
+		return
+	}
+	if _, ok := h.registeredUser(ctx, b, userID); !ok {
+		return
+	}
+	if err := h.chatState.SetEditField(ctx, userID, field.name); err != nil {
+		fmt.Println("Ошибка при сохранении редактируемого поля:", err)
+		return
+	}
+
+	kb := keyboard.NewReplyKeyboard()
+	if len(field.buttons) > 0 {
+		kb.AddRow(field.buttons...)
+	}
+	kb.AddRow(keyboard.NewReplyButton(profileCancelText))
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        field.prompt,
+		ReplyMarkup: kb.Build(),
+	})
+}
+
+// handleProfileEdit принимает новое значение поля, выбранного в /edit.
+// Возвращает false, если пользователь сейчас ничего не редактирует.
+func (h *Handler) handleProfileEdit(ctx context.Context, b *bot.Bot, msg *models.Message) bool {
+	userID := msg.From.ID
+	name, err := h.chatState.GetEditField(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении редактируемого поля:", err)
+		return false
+	}
+	if name == "" {
+		return false
+	}
+	field, ok := profileField(name)
+	if !ok || msg.Text == profileCancelText {
+		if err := h.chatState.ClearEditField(ctx, userID); err != nil {
+			fmt.Println("Ошибка при удалении редактируемого поля:", err)
+		}
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      userID,
+			Text:        "Өзгеріс болдырылмады.",
+			ReplyMarkup: keyboard.RemoveReplyKeyboard(),
+		})
+		return true
+	}
+
+	if field.extra != nil && field.extra(h, ctx, b, msg) {
+		return true
+	}
+
+	hint, err := field.apply(h, ctx, b, msg)
+	if err != nil {
+		fmt.Printf("Ошибка при сохранении поля %s: %v\n", field.name, err)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Деректерді сақтау кезінде қате пайда болды, қайталап көріңіз.",
+		})
+		return true
+	}
+	if hint != "" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   hint,
+		})
+		return true
+	}
+	h.profileEditDone(ctx, b, userID)
+	return true
+}
+
+// profileEditDone завершает редактирование поля и показывает обновлённую анкету.
+func (h *Handler) profileEditDone(ctx context.Context, b *bot.Bot, userID int64) {
+	if err := h.chatState.ClearEditField(ctx, userID); err != nil {
+		fmt.Println("Ошибка при удалении редактируемого поля:", err)
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        "✓ Сақталды.",
+		ReplyMarkup: keyboard.RemoveReplyKeyboard(),
+	})
+	if user, err := h.userRepo.GetUser(userID); err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+	} else {
+		h.sendProfileCard(ctx, b, userID, user, editButtonKeyboard())
+	}
+}
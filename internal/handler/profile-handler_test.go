@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"tanysu-bot/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileField(t *testing.T) {
+	for _, name := range []string{"nickname", "sex", "age", "photo", "location", "bio"} {
+		field, ok := profileField(name)
+		assert.True(t, ok, name)
+		assert.NotEmpty(t, field.label, name)
+	}
+	_, ok := profileField("contact")
+	assert.False(t, ok)
+
+	// Поле "Өзі туралы" не должно попадать в мастер регистрации.
+	assert.Equal(t, -1, registrationStepIndex("bio"))
+}
+
+func TestRenderProfile(t *testing.T) {
+	user := &repository.User{UserNickname: "tanysu", UserSex: sexFemale, UserAge: 25}
+	assert.Equal(t, "👤 @tanysu\nЖынысы: Әйел\nЖасы: 25\nОрны: көрсетілмеген", renderProfile(user))
+
+	user.UserGeo = "43.25000,76.95000"
+	user.Bio = "Сәлем!"
+	assert.Equal(t, "👤 @tanysu\nЖынысы: Әйел\nЖасы: 25\nОрны: бөлісілген\nӨзі туралы: Сәлем!", renderProfile(user))
+
+	user.UserCity = "Алматы"
+	assert.Contains(t, renderProfile(user), "Орны: Алматы")
+}
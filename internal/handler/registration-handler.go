@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"tanysu-bot/internal/keyboard"
+	"tanysu-bot/internal/repository"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Тексты служебных кнопок мастера регистрации.
+const (
+	registrationBackText = "⬅️ Артқа"
+	registrationSkipText = "⏭ Өткізу"
+)
+
+// Пол выбирается кнопками, поэтому других значений в БД не бывает.
+const (
+	sexMale   = "Еркек"
+	sexFemale = "Әйел"
+)
+
+// Ограничения на длину никнейма.
+const (
+	nicknameMinLen = 2
+	nicknameMaxLen = 32
+)
+
+// registrationStep — один шаг мастера регистрации; те же шаги используются в /edit.
+type registrationStep struct {
+	name string
+	// label — название поля на кнопке /edit.
+	label  string
+	prompt string
+	// buttons — варианты ответа, которые показываются над "Артқа" и "Өткізу".
+	buttons []models.KeyboardButton
+	// filled сообщает, что поле уже заполнено; такой шаг можно пропустить.
+	filled func(user *repository.User) bool
+	// apply проверяет ответ и сохраняет его. Непустой hint означает, что ответ не подошёл
+	// и пользователю нужно показать подсказку.
+	apply func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) (hint string, err error)
+	// extra обрабатывает дополнительные кнопки шага. true — сообщение обработано, но шаг не завершён.
+	extra func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) bool
+}
+
+// registrationSteps — шаги в порядке прохождения. Названия шагов хранятся в Redis,
+// поэтому менять их нельзя.
+var registrationSteps = []registrationStep{
+	{
+		name:   "nickname",
+		label:  "Лақап аты",
+		prompt: "Лақап атыңызды жазыңыз, мысалы: @nickname",
+		filled: func(user *repository.User) bool { return user.UserNickname != "" },
+		apply: func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) (string, error) {
+			nickname, ok := parseNickname(msg.Text)
+			if !ok {
+				return fmt.Sprintf("Лақап аты бір жолда, %d-%d таңбадан тұруы керек. Мысал: @nickname", nicknameMinLen, nicknameMaxLen), nil
+			}
+			return "", h.userRepo.UpdateNickname(msg.From.ID, nickname)
+		},
+	},
+	{
+		name:    "sex",
+		label:   "Жынысы",
+		prompt:  "Жынысыңызды таңдаңыз.",
+		buttons: []models.KeyboardButton{keyboard.NewReplyButton(sexMale), keyboard.NewReplyButton(sexFemale)},
+		filled:  func(user *repository.User) bool { return user.UserSex != "" },
+		apply: func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) (string, error) {
+			sex, ok := parseSex(msg.Text)
+			if !ok {
+				return "Төмендегі батырмалардың бірін басыңыз: " + sexMale + " немесе " + sexFemale + ".", nil
+			}
+			return "", h.userRepo.UpdateUserSex(msg.From.ID, sex)
+		},
+	},
+	{
+		name:   "age",
+		label:  "Жасы",
+		prompt: "Жасыңызды жазыңыз, мысалы: 25",
+		filled: func(user *repository.User) bool { return user.UserAge != 0 },
+		apply: func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) (string, error) {
+			age, ok := parseAge(msg.Text)
+			if !ok {
+				return "Жасыңызды сан түрінде жазыңыз, мысалы: 25", nil
+			}
+			if hint := ageHint(h.config, age); hint != "" {
+				return hint, nil
+			}
+			// Собеседник подобран по возрасту, поэтому посреди чата возраст не меняется.
+			partnerID, err := h.chatState.GetUserPartner(ctx, msg.From.ID)
+			if err != nil {
+				return "", err
+			}
+			if partnerID != 0 {
+				return "Чат кезінде жасты өзгертуге болмайды. Алдымен чаттан шығыңыз.", nil
+			}
+			return "", h.userRepo.UpdateUserAge(msg.From.ID, age)
+		},
+	},
+	{
+		name:   "photo",
+		label:  "Аватар",
+		prompt: "Аватар ретінде фото жіберіңіз.",
+		filled: func(user *repository.User) bool { return user.AvaFileID != "" },
+		apply: func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) (string, error) {
+			if len(msg.Photo) == 0 {
+				return "Өтінеміз, фото жіберіңіз.", nil
+			}
+			return "", h.saveAvatar(ctx, b, msg.From.ID, msg.Photo[len(msg.Photo)-1])
+		},
+	},
+	{
+		name:    "location",
+		label:   "Орны",
+		prompt:  "Серіктес табу үшін орныңызды бөлісіңіз немесе қаланы тізімнен таңдаңыз.",
+		buttons: []models.KeyboardButton{keyboard.NewLocationButton("📍 Орынды бөлісу"), keyboard.NewReplyButton(cityPickerText)},
+		filled:  func(user *repository.User) bool { return user.UserGeo != "" },
+		apply: func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) (string, error) {
+			if msg.Location == nil {
+				return "Өтінеміз, \"📍 Орынды бөлісу\" немесе \"" + cityPickerText + "\" батырмасын басыңыз.", nil
+			}
+			return "", h.saveLocation(msg.From.ID, msg.Location.Latitude, msg.Location.Longitude)
+		},
+		extra: func(h *Handler, ctx context.Context, b *bot.Bot, msg *models.Message) bool {
+			if msg.Text != cityPickerText {
+				return false
+			}
+			h.sendCityPicker(ctx, b, msg.From.ID)
+			return true
+		},
+	},
+}
+
+// registrationStepIndex возвращает номер шага по названию или -1.
+func registrationStepIndex(name string) int {
+	for i, step := range registrationSteps {
+		if step.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextRegistrationStep возвращает первый незаполненный шаг после current, затем с начала.
+// -1 означает, что все поля заполнены.
+func nextRegistrationStep(user *repository.User, current int) int {
+	n := len(registrationSteps)
+	for i := 1; i <= n; i++ {
+		idx := (current + i + n) % n
+		if !registrationSteps[idx].filled(user) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// parseNickname убирает "@" в начале и проверяет длину никнейма.
+func parseNickname(text string) (string, bool) {
+	nickname := strings.TrimPrefix(strings.TrimSpace(text), "@")
+	length := utf8.RuneCountInString(nickname)
+	if length < nicknameMinLen || length > nicknameMaxLen || strings.ContainsAny(nickname, "\n/") {
+		return "", false
+	}
+	return nickname, true
+}
+
+// parseSex принимает только варианты с кнопок, без учёта регистра.
+func parseSex(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	for _, sex := range []string{sexMale, sexFemale} {
+		if strings.EqualFold(text, sex) {
+			return sex, true
+		}
+	}
+	return "", false
+}
+
+// parseAge разбирает возраст. Допустимые границы задаются в настройках и проверяются в ageHint.
+func parseAge(text string) (int, bool) {
+	age, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || age <= 0 {
+		return 0, false
+	}
+	return age, true
+}
+
+// RegistrationHandler ведёт пользователя по шагам регистрации: никнейм, пол, возраст, фото и местоположение.
+// Текущий шаг хранится в Redis, поэтому после перезапуска бота регистрация продолжается с того же места.
+func (h *Handler) RegistrationHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	msg := update.Message
+	if msg == nil {
+		return
+	}
+	userID := msg.From.ID
+
+	user, err := h.userRepo.GetUser(userID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return
+	}
+
+	stepName, err := h.chatState.GetRegistrationStep(ctx, userID)
+	if err != nil {
+		fmt.Println("Ошибка при получении шага регистрации:", err)
+		return
+	}
+	current := registrationStepIndex(stepName)
+	if current < 0 {
+		// Регистрация только начинается: это сообщение — не ответ на вопрос.
+		h.showRegistrationStep(ctx, b, userID, user, nextRegistrationStep(user, -1))
+		return
+	}
+	step := registrationSteps[current]
+
+	switch msg.Text {
+	case registrationBackText:
+		h.showRegistrationStep(ctx, b, userID, user, max(current-1, 0))
+		return
+	case registrationSkipText:
+		if !step.filled(user) {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: userID,
+				Text:   "Бұл қадамды өткізіп жіберуге болмайды.",
+			})
+			return
+		}
+		h.showRegistrationStep(ctx, b, userID, user, nextRegistrationStep(user, current))
+		return
+	}
+	if step.extra != nil && step.extra(h, ctx, b, msg) {
+		return
+	}
+
+	hint, err := step.apply(h, ctx, b, msg)
+	if err != nil {
+		fmt.Printf("Ошибка при сохранении шага регистрации %s: %v\n", step.name, err)
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   "Деректерді сақтау кезінде қате пайда болды, қайталап көріңіз.",
+		})
+		return
+	}
+	if hint != "" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: userID,
+			Text:   hint,
+		})
+		return
+	}
+	h.registrationStepDone(ctx, b, userID, current)
+}
+
+// registrationStepDone переходит к следующему незаполненному шагу после сохранённого.
+func (h *Handler) registrationStepDone(ctx context.Context, b *bot.Bot, userID int64, current int) {
+	user, err := h.userRepo.GetUser(userID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return
+	}
+	h.showRegistrationStep(ctx, b, userID, user, nextRegistrationStep(user, current))
+}
+
+// showRegistrationStep запоминает шаг и задаёт его вопрос. idx = -1 завершает регистрацию.
+func (h *Handler) showRegistrationStep(ctx context.Context, b *bot.Bot, userID int64, user *repository.User, idx int) {
+	if idx < 0 {
+		h.finishRegistration(ctx, b, userID, user)
+		return
+	}
+	step := registrationSteps[idx]
+	if err := h.chatState.SetRegistrationStep(ctx, userID, step.name); err != nil {
+		fmt.Println("Ошибка при сохранении шага регистрации:", err)
+		return
+	}
+
+	kb := keyboard.NewReplyKeyboard()
+	if len(step.buttons) > 0 {
+		kb.AddRow(step.buttons...)
+	}
+	var controls []models.KeyboardButton
+	if idx > 0 {
+		controls = append(controls, keyboard.NewReplyButton(registrationBackText))
+	}
+	if step.filled(user) {
+		controls = append(controls, keyboard.NewReplyButton(registrationSkipText))
+	}
+	if len(controls) > 0 {
+		kb.AddRow(controls...)
+	}
+
+	var markup models.ReplyMarkup = kb.Build()
+	if len(step.buttons) == 0 && len(controls) == 0 {
+		markup = keyboard.RemoveReplyKeyboard()
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        fmt.Sprintf("Тіркеу (%d/%d). %s", idx+1, len(registrationSteps), step.prompt),
+		ReplyMarkup: markup,
+	})
+}
+
+// finishRegistration убирает состояние мастера и показывает пользователю его анкету.
+func (h *Handler) finishRegistration(ctx context.Context, b *bot.Bot, userID int64, user *repository.User) {
+	if err := h.chatState.ClearRegistrationStep(ctx, userID); err != nil {
+		fmt.Println("Ошибка при удалении шага регистрации:", err)
+	}
+
+	h.sendProfileCard(ctx, b, userID, user, keyboard.RemoveReplyKeyboard())
+
+	kb := keyboard.NewKeyboard()
+	kb.AddRow(keyboard.NewInlineButton("💬 Chat", "chat"))
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      userID,
+		Text:        "Тіркеу сәтті аяқталды! Сөйлесуші табу үшін '💬 Chat' батырмасын басыңыз.",
+		ReplyMarkup: kb.Build(),
+	})
+}
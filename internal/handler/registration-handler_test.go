@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"tanysu-bot/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextRegistrationStep(t *testing.T) {
+	user := &repository.User{}
+	assert.Equal(t, registrationStepIndex("nickname"), nextRegistrationStep(user, -1))
+
+	// Заполненные шаги пропускаются.
+	user.UserNickname = "nick"
+	user.UserSex = sexMale
+	assert.Equal(t, registrationStepIndex("age"), nextRegistrationStep(user, registrationStepIndex("nickname")))
+
+	// После последнего шага возвращаемся к незаполненным в начале.
+	user.UserNickname = ""
+	user.UserAge = 25
+	user.AvaFileID = "file"
+	user.UserGeo = "43.25000,76.95000"
+	assert.Equal(t, registrationStepIndex("nickname"), nextRegistrationStep(user, registrationStepIndex("location")))
+
+	user.UserNickname = "nick"
+	assert.Equal(t, -1, nextRegistrationStep(user, registrationStepIndex("location")))
+}
+
+func TestRegistrationParsers(t *testing.T) {
+	nickname, ok := parseNickname("  @tanysu ")
+	assert.True(t, ok)
+	assert.Equal(t, "tanysu", nickname)
+	_, ok = parseNickname("a")
+	assert.False(t, ok)
+	_, ok = parseNickname("/start")
+	assert.False(t, ok)
+
+	sex, ok := parseSex("әйел")
+	assert.True(t, ok)
+	assert.Equal(t, sexFemale, sex)
+	_, ok = parseSex("иә")
+	assert.False(t, ok)
+
+	age, ok := parseAge(" 25 ")
+	assert.True(t, ok)
+	assert.Equal(t, 25, age)
+	// Верхняя граница задаётся в настройках и проверяется в ageHint.
+	age, ok = parseAge("300")
+	assert.True(t, ok)
+	assert.Equal(t, 300, age)
+	for _, text := range []string{"0", "-3", "жиырма"} {
+		_, ok = parseAge(text)
+		assert.False(t, ok, text)
+	}
+}
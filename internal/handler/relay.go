@@ -0,0 +1,507 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"tanysu-bot/internal/sender"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// relayContent описывает, как переслать сообщение конкретного типа.
+type relayContent struct {
+	// deleteLabel — текст кнопки удаления для этого типа сообщения.
+	deleteLabel string
+	// copyTo отправляет копию сообщения в чат (собеседнику или отправителю в режиме эха).
+	copyTo func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error)
+	// toChannel возвращает отправки копии сообщения в канал; выполняет их очередь отправки.
+	toChannel func(b *bot.Bot, channel string) []sender.Request
+}
+
+// newRelayContent подбирает способ пересылки по типу сообщения.
+// spoiler скрывает фото и видео под спойлером, diceReroll бросает кубик собеседнику заново
+// вместо текста с результатом отправителя. Возвращает false, если тип сообщения не поддерживается.
+func newRelayContent(msg *models.Message, senderIdentifier, partnerIdentifier string, spoiler, diceReroll bool) (*relayContent, bool) {
+	var caption string
+	if msg.Caption != "" {
+		caption = fmt.Sprintf("%s: %s", senderIdentifier, msg.Caption)
+	}
+	channelHeader := fmt.Sprintf("Сообщение от %s к %s", senderIdentifier, partnerIdentifier)
+
+	switch {
+	// 1. Текст.
+	case msg.Text != "":
+		fmt.Printf("TEXT | User=%s | Text=%q\n", senderIdentifier, msg.Text)
+		text := fmt.Sprintf("%s: %s", senderIdentifier, msg.Text)
+		return &relayContent{
+			deleteLabel: "⛔️ Хабарламыны жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return b.SendMessage(ctx, &bot.SendMessageParams{
+					ChatID:         chatID,
+					Text:           text,
+					ReplyMarkup:    markup,
+					ProtectContent: true,
+				})
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{channelText(b, channel, fmt.Sprintf("%s:\n%s", channelHeader, msg.Text))}
+			},
+		}, true
+
+	// 2. Фото.
+	case msg.Photo != nil:
+		photoID := msg.Photo[len(msg.Photo)-1].FileID
+		fmt.Printf("PHOTO | User=%s | FileID=%s | Caption=%q\n", senderIdentifier, photoID, msg.Caption)
+		photoCaption := withDefaultCaption(senderIdentifier, caption, "фото")
+		sendPhoto := func(ctx context.Context, b *bot.Bot, chatID any, caption string, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendPhoto(ctx, &bot.SendPhotoParams{
+				ChatID:         chatID,
+				Photo:          &models.InputFileString{Data: photoID},
+				Caption:        caption,
+				HasSpoiler:     spoiler,
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Фотоны жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendPhoto(ctx, b, chatID, photoCaption, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{func(ctx context.Context) (*models.Message, error) {
+					return sendPhoto(ctx, b, channel, fmt.Sprintf("%s:\n%s", channelHeader, photoCaption), nil)
+				}}
+			},
+		}, true
+
+	// 3. Видео.
+	case msg.Video != nil:
+		fmt.Printf("VIDEO | User=%s | FileID=%s | Caption=%q\n", senderIdentifier, msg.Video.FileID, msg.Caption)
+		videoCaption := withDefaultCaption(senderIdentifier, caption, "видео")
+		sendVideo := func(ctx context.Context, b *bot.Bot, chatID any, caption string, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendVideo(ctx, &bot.SendVideoParams{
+				ChatID:         chatID,
+				Video:          &models.InputFileString{Data: msg.Video.FileID},
+				Caption:        caption,
+				HasSpoiler:     spoiler,
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Видеоны жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendVideo(ctx, b, chatID, videoCaption, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{func(ctx context.Context) (*models.Message, error) {
+					return sendVideo(ctx, b, channel, fmt.Sprintf("%s:\n%s", channelHeader, videoCaption), nil)
+				}}
+			},
+		}, true
+
+	// 4. Голосовое сообщение.
+	case msg.Voice != nil:
+		fmt.Printf("VOICE | User=%s | FileID=%s | Caption=%q\n", senderIdentifier, msg.Voice.FileID, msg.Caption)
+		voiceCaption := withDefaultCaption(senderIdentifier, caption, "голосовое сообщение")
+		sendVoice := func(ctx context.Context, b *bot.Bot, chatID any, caption string, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendVoice(ctx, &bot.SendVoiceParams{
+				ChatID:         chatID,
+				Voice:          &models.InputFileString{Data: msg.Voice.FileID},
+				Caption:        caption,
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Дыбыстық хабарламаны жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendVoice(ctx, b, chatID, voiceCaption, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{func(ctx context.Context) (*models.Message, error) {
+					return sendVoice(ctx, b, channel, fmt.Sprintf("%s:\n%s", channelHeader, voiceCaption), nil)
+				}}
+			},
+		}, true
+
+	// 5. Видео-сообщение (VideoNote).
+	case msg.VideoNote != nil:
+		fmt.Printf("VIDEO_NOTE | User=%s | FileID=%s\n", senderIdentifier, msg.VideoNote.FileID)
+		sendVideoNote := func(ctx context.Context, b *bot.Bot, chatID any, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendVideoNote(ctx, &bot.SendVideoNoteParams{
+				ChatID:         chatID,
+				VideoNote:      &models.InputFileString{Data: msg.VideoNote.FileID},
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Видео хабарламаны жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendVideoNote(ctx, b, chatID, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{
+					func(ctx context.Context) (*models.Message, error) {
+						return sendVideoNote(ctx, b, channel, nil)
+					},
+					channelText(b, channel, fmt.Sprintf("%s: Видео сообщение", channelHeader)),
+				}
+			},
+		}, true
+
+	// 6. Анимация (GIF). Проверяем раньше документа: у анимации тоже заполнено поле Document.
+	case msg.Animation != nil:
+		fmt.Printf("ANIMATION | User=%s | FileID=%s | Caption=%q\n", senderIdentifier, msg.Animation.FileID, msg.Caption)
+		animationCaption := withDefaultCaption(senderIdentifier, caption, "GIF")
+		sendAnimation := func(ctx context.Context, b *bot.Bot, chatID any, caption string, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendAnimation(ctx, &bot.SendAnimationParams{
+				ChatID:         chatID,
+				Animation:      &models.InputFileString{Data: msg.Animation.FileID},
+				Caption:        caption,
+				HasSpoiler:     spoiler,
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ GIF-ті жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendAnimation(ctx, b, chatID, animationCaption, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{func(ctx context.Context) (*models.Message, error) {
+					return sendAnimation(ctx, b, channel, fmt.Sprintf("%s:\n%s", channelHeader, animationCaption), nil)
+				}}
+			},
+		}, true
+
+	// 7. Документ.
+	case msg.Document != nil:
+		fmt.Printf("DOCUMENT | User=%s | FileID=%s | Caption=%q\n", senderIdentifier, msg.Document.FileID, msg.Caption)
+		docCaption := withDefaultCaption(senderIdentifier, caption, "документ")
+		sendDocument := func(ctx context.Context, b *bot.Bot, chatID any, caption string, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendDocument(ctx, &bot.SendDocumentParams{
+				ChatID:         chatID,
+				Document:       &models.InputFileString{Data: msg.Document.FileID},
+				Caption:        caption,
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Құжатты жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendDocument(ctx, b, chatID, docCaption, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{func(ctx context.Context) (*models.Message, error) {
+					return sendDocument(ctx, b, channel, fmt.Sprintf("%s:\n%s", channelHeader, docCaption), nil)
+				}}
+			},
+		}, true
+
+	// 8. Аудио.
+	case msg.Audio != nil:
+		fmt.Printf("AUDIO | User=%s | FileID=%s | Caption=%q\n", senderIdentifier, msg.Audio.FileID, msg.Caption)
+		audioCaption := withDefaultCaption(senderIdentifier, caption, "аудио")
+		sendAudio := func(ctx context.Context, b *bot.Bot, chatID any, caption string, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendAudio(ctx, &bot.SendAudioParams{
+				ChatID:         chatID,
+				Audio:          &models.InputFileString{Data: msg.Audio.FileID},
+				Caption:        caption,
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Аудионы жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendAudio(ctx, b, chatID, audioCaption, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{func(ctx context.Context) (*models.Message, error) {
+					return sendAudio(ctx, b, channel, fmt.Sprintf("%s:\n%s", channelHeader, audioCaption), nil)
+				}}
+			},
+		}, true
+
+	// 9. Место (venue). Проверяем раньше локации: у venue тоже заполнено поле Location.
+	case msg.Venue != nil:
+		venue := msg.Venue
+		fmt.Printf("VENUE | User=%s | Title=%q | Address=%q\n", senderIdentifier, venue.Title, venue.Address)
+		return &relayContent{
+			deleteLabel: "⛔️ Орынды жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return b.SendVenue(ctx, &bot.SendVenueParams{
+					ChatID:          chatID,
+					Latitude:        venue.Location.Latitude,
+					Longitude:       venue.Location.Longitude,
+					Title:           venue.Title,
+					Address:         venue.Address,
+					FoursquareID:    venue.FoursquareID,
+					FoursquareType:  venue.FoursquareType,
+					GooglePlaceID:   venue.GooglePlaceID,
+					GooglePlaceType: venue.GooglePlaceType,
+					ReplyMarkup:     markup,
+					ProtectContent:  true,
+				})
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{channelText(b, channel, fmt.Sprintf("%s:\nМесто: %s\nАдрес: %s\nЛокация: %.5f, %.5f",
+					channelHeader, venue.Title, venue.Address, venue.Location.Latitude, venue.Location.Longitude))}
+			},
+		}, true
+
+	// 10. Локация (в том числе live-локация).
+	case msg.Location != nil:
+		location := msg.Location
+		fmt.Printf("LOCATION | User=%s | Lat=%.5f | Long=%.5f | Live=%d\n", senderIdentifier, location.Latitude, location.Longitude, location.LivePeriod)
+		return &relayContent{
+			deleteLabel: "⛔️ Гео-локацияны жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return b.SendLocation(ctx, &bot.SendLocationParams{
+					ChatID:               chatID,
+					Latitude:             location.Latitude,
+					Longitude:            location.Longitude,
+					HorizontalAccuracy:   location.HorizontalAccuracy,
+					LivePeriod:           location.LivePeriod,
+					Heading:              location.Heading,
+					ProximityAlertRadius: location.ProximityAlertRadius,
+					ReplyMarkup:          markup,
+					ProtectContent:       true,
+				})
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{channelText(b, channel, fmt.Sprintf("%s:\nЛокация: %.5f, %.5f", channelHeader, location.Latitude, location.Longitude))}
+			},
+		}, true
+
+	// 11. Стикер.
+	case msg.Sticker != nil:
+		fmt.Printf("STICKER | User=%s | FileID=%s\n", senderIdentifier, msg.Sticker.FileID)
+		sendSticker := func(ctx context.Context, b *bot.Bot, chatID any, markup models.ReplyMarkup) (*models.Message, error) {
+			return b.SendSticker(ctx, &bot.SendStickerParams{
+				ChatID:         chatID,
+				Sticker:        &models.InputFileString{Data: msg.Sticker.FileID},
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Стикерді жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendSticker(ctx, b, chatID, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{
+					func(ctx context.Context) (*models.Message, error) {
+						return sendSticker(ctx, b, channel, nil)
+					},
+					channelText(b, channel, fmt.Sprintf("%s: Стикер", channelHeader)),
+				}
+			},
+		}, true
+
+	// 12. Контакт.
+	case msg.Contact != nil:
+		contact := msg.Contact
+		fmt.Printf("CONTACT | User=%s | Phone=%s | FirstName=%s | LastName=%s\n",
+			senderIdentifier,
+			contact.PhoneNumber,
+			contact.FirstName,
+			contact.LastName,
+		)
+		contactText := fmt.Sprintf("%s отправил(а) контакт:\nТел: %s\nИмя: %s %s",
+			senderIdentifier,
+			contact.PhoneNumber,
+			contact.FirstName,
+			contact.LastName,
+		)
+		return &relayContent{
+			deleteLabel: "⛔️ Контактіні жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return b.SendMessage(ctx, &bot.SendMessageParams{
+					ChatID:         chatID,
+					Text:           contactText,
+					ReplyMarkup:    markup,
+					ProtectContent: true,
+				})
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{channelText(b, channel, fmt.Sprintf("%s:\nКонтакт:\nТел: %s\nИмя: %s %s",
+					channelHeader,
+					contact.PhoneNumber,
+					contact.FirstName,
+					contact.LastName,
+				))}
+			},
+		}, true
+
+	// 13. Кубик и другие анимированные эмодзи-игры.
+	case msg.Dice != nil:
+		dice := msg.Dice
+		fmt.Printf("DICE | User=%s | Emoji=%s | Value=%d\n", senderIdentifier, dice.Emoji, dice.Value)
+		resultText := fmt.Sprintf("%s %s: %d", dice.Emoji, senderIdentifier, dice.Value)
+		return &relayContent{
+			deleteLabel: "⛔️ Ойынды жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				// Бот не может отправить кубик с заданным значением: либо бросает заново, либо пишет результат.
+				if diceReroll {
+					return b.SendDice(ctx, &bot.SendDiceParams{
+						ChatID:         chatID,
+						Emoji:          dice.Emoji,
+						ReplyMarkup:    markup,
+						ProtectContent: true,
+					})
+				}
+				return b.SendMessage(ctx, &bot.SendMessageParams{
+					ChatID:         chatID,
+					Text:           resultText,
+					ReplyMarkup:    markup,
+					ProtectContent: true,
+				})
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{channelText(b, channel, fmt.Sprintf("%s: %s %d", channelHeader, dice.Emoji, dice.Value))}
+			},
+		}, true
+
+	// 14. Игра: переслать чужую игру бот не может, поэтому отправляем её описание.
+	case msg.Game != nil:
+		game := msg.Game
+		fmt.Printf("GAME | User=%s | Title=%q\n", senderIdentifier, game.Title)
+		gameText := fmt.Sprintf("%s отправил(а) игру 🎮 %s\n%s", senderIdentifier, game.Title, game.Description)
+		return &relayContent{
+			deleteLabel: "⛔️ Ойынды жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return b.SendMessage(ctx, &bot.SendMessageParams{
+					ChatID:         chatID,
+					Text:           gameText,
+					ReplyMarkup:    markup,
+					ProtectContent: true,
+				})
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{channelText(b, channel, fmt.Sprintf("%s: Игра %q", channelHeader, game.Title))}
+			},
+		}, true
+
+	// 15. Платное медиа: пересылаем доступные боту фото и видео с той же ценой в звёздах.
+	case msg.PaidMedia != nil:
+		paid := msg.PaidMedia
+		fmt.Printf("PAID_MEDIA | User=%s | Stars=%d | Items=%d\n", senderIdentifier, paid.StarCount, len(paid.PaidMedia))
+		var media []models.InputPaidMedia
+		for _, item := range paid.PaidMedia {
+			switch {
+			case item.Photo != nil && len(item.Photo.Photo) > 0:
+				media = append(media, &models.InputPaidMediaPhoto{Media: item.Photo.Photo[len(item.Photo.Photo)-1].FileID})
+			case item.Video != nil:
+				media = append(media, &models.InputPaidMediaVideo{Media: item.Video.Video.FileID})
+			}
+		}
+		paidCaption := withDefaultCaption(senderIdentifier, caption, fmt.Sprintf("платное медиа (%d ⭐)", paid.StarCount))
+		sendPaid := func(ctx context.Context, b *bot.Bot, chatID any, caption string, markup models.ReplyMarkup) (*models.Message, error) {
+			// Содержимое недоступно боту (только превью) — сообщаем о нём текстом.
+			if len(media) == 0 {
+				return b.SendMessage(ctx, &bot.SendMessageParams{
+					ChatID:         chatID,
+					Text:           caption,
+					ReplyMarkup:    markup,
+					ProtectContent: true,
+				})
+			}
+			return b.SendPaidMedia(ctx, &bot.SendPaidMediaParams{
+				ChatID:         chatID,
+				StarCount:      paid.StarCount,
+				Media:          media,
+				Caption:        caption,
+				ReplyMarkup:    markup,
+				ProtectContent: true,
+			})
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Медианы жою!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendPaid(ctx, b, chatID, paidCaption, markup)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				return []sender.Request{func(ctx context.Context) (*models.Message, error) {
+					return sendPaid(ctx, b, channel, fmt.Sprintf("%s:\n%s", channelHeader, paidCaption), nil)
+				}}
+			},
+		}, true
+
+	// 16. Опрос: переносим все настройки, включая режим викторины.
+	case msg.Poll != nil:
+		poll := msg.Poll
+		fmt.Printf("POLL | User=%s | Question=%q | Options=%d | Type=%s\n", senderIdentifier, poll.Question, len(poll.Options), poll.Type)
+		var pollOptions []models.InputPollOption
+		for _, o := range poll.Options {
+			pollOptions = append(pollOptions, models.InputPollOption{Text: o.Text, TextEntities: o.TextEntities})
+		}
+		sendPoll := func(ctx context.Context, b *bot.Bot, chatID any, markup models.ReplyMarkup, anonymous bool) (*models.Message, error) {
+			params := &bot.SendPollParams{
+				ChatID:                chatID,
+				Question:              poll.Question,
+				QuestionEntities:      poll.QuestionEntities,
+				Options:               pollOptions,
+				IsAnonymous:           &anonymous,
+				Type:                  poll.Type,
+				AllowsMultipleAnswers: poll.AllowsMultipleAnswers,
+				CorrectOptionID:       poll.CorrectOptionID,
+				Explanation:           poll.Explanation,
+				ExplanationEntities:   poll.ExplanationEntities,
+				OpenPeriod:            poll.OpenPeriod,
+				IsClosed:              poll.IsClosed,
+				ReplyMarkup:           markup,
+				ProtectContent:        true,
+			}
+			// Прошедшую дату закрытия Telegram не примет.
+			if poll.CloseDate > int(time.Now().Unix()) {
+				params.CloseDate = poll.CloseDate
+			}
+			return b.SendPoll(ctx, params)
+		}
+		return &relayContent{
+			deleteLabel: "⛔️ Хабарламыны жою опрос!",
+			copyTo: func(ctx context.Context, b *bot.Bot, chatID int64, markup models.ReplyMarkup) (*models.Message, error) {
+				return sendPoll(ctx, b, chatID, markup, poll.IsAnonymous)
+			},
+			toChannel: func(b *bot.Bot, channel string) []sender.Request {
+				// В каналах бывают только анонимные опросы.
+				return []sender.Request{
+					func(ctx context.Context) (*models.Message, error) {
+						return sendPoll(ctx, b, channel, nil, true)
+					},
+					channelText(b, channel, fmt.Sprintf("%s: Опрос\nВопрос: %s", channelHeader, poll.Question)),
+				}
+			},
+		}, true
+	}
+
+	return nil, false
+}
+
+// channelText возвращает отправку текстовой копии в канал.
+func channelText(b *bot.Bot, channel, text string) sender.Request {
+	return func(ctx context.Context) (*models.Message, error) {
+		return b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:         channel,
+			Text:           text,
+			ProtectContent: true,
+		})
+	}
+}
+
+// withDefaultCaption формирует подпись для медиа-сообщения, если она отсутствует.
+func withDefaultCaption(username, caption, mediaType string) string {
+	if caption != "" {
+		return caption
+	}
+	return fmt.Sprintf("@%s отправил(а) %s", username, mediaType)
+}
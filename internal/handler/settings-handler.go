@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"tanysu-bot/internal/repository"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// EchoToggleHandler включает или выключает копию каждого отправленного сообщения у отправителя.
+func (h *Handler) EchoToggleHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	h.toggleSetting(ctx, b, update, repository.SettingEcho,
+		"Енді жіберген хабарламаларыңыздың көшірмесі сізге де келеді.",
+		"Хабарламалардың көшірмесі өшірілді.",
+	)
+}
+
+// toggleSetting переключает настройку чата и сообщает пользователю новое состояние.
+func (h *Handler) toggleSetting(ctx context.Context, b *bot.Bot, update *models.Update, name, onText, offText string) {
+	h.ensureUserInDB(update)
+
+	userID := update.Message.From.ID
+	enabled, err := h.chatState.GetUserSetting(ctx, userID, name)
+	if err != nil {
+		fmt.Printf("Ошибка при чтении настройки %s: %v\n", name, err)
+		return
+	}
+
+	enabled = !enabled
+	if err := h.chatState.SetUserSetting(ctx, userID, name, enabled); err != nil {
+		fmt.Printf("Ошибка при сохранении настройки %s: %v\n", name, err)
+		return
+	}
+
+	text := onText
+	if !enabled {
+		text = offText
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: userID,
+		Text:   text,
+	})
+}
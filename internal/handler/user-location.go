@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"tanysu-bot/internal/geo"
+	"tanysu-bot/internal/repository"
+	"time"
+)
+
+// cityStatsTop — сколько городов выводить в статистике; остальные суммируются.
+const cityStatsTop = 10
+
+// saveLocation сохраняет координаты пользователя, огрублённые до GeoPrecisionKm, и город.
+// Город определяется по точным координатам, но сами они в базу не попадают.
+func (h *Handler) saveLocation(userID int64, lat, lon float64) error {
+	city := ""
+	if c, ok := geo.Lookup(lat, lon); ok {
+		city = c.Name
+	}
+	lat, lon = geo.Fuzz(lat, lon, h.config.GeoPrecisionKm)
+	return h.userRepo.UpdateUserLocation(userID, geo.FormatPoint(lat, lon), city, false)
+}
+
+// saveApproxLocation сохраняет вместо координат центр выбранного города и помечает их как приблизительные.
+func (h *Handler) saveApproxLocation(userID int64, city geo.City) error {
+	return h.userRepo.UpdateUserLocation(userID, geo.FormatPoint(city.Lat, city.Lon), city.Name, true)
+}
+
+// BackfillCities определяет город для пользователей, сохранивших геолокацию раньше,
+// чем появился справочник городов.
+func (h *Handler) BackfillCities() {
+	missing, err := h.userRepo.UsersWithoutCity()
+	if err != nil {
+		fmt.Println("Ошибка при получении пользователей без города:", err)
+		return
+	}
+	filled := 0
+	for userID, point := range missing {
+		lat, lon, ok := geo.ParsePoint(point)
+		if !ok {
+			continue
+		}
+		city, ok := geo.Lookup(lat, lon)
+		if !ok {
+			continue
+		}
+		if err := h.userRepo.UpdateUserCity(userID, city.Name); err != nil {
+			fmt.Println("Ошибка при сохранении города:", err)
+			continue
+		}
+		filled++
+	}
+	if filled > 0 {
+		fmt.Printf("Города определены для %d пользователей\n", filled)
+	}
+}
+
+// FuzzStoredLocations огрубляет координаты, сохранённые до появления GeoPrecisionKm
+// или при более мелкой сетке. Уже огрублённые точки не меняются.
+func (h *Handler) FuzzStoredLocations() {
+	points, err := h.userRepo.PreciseLocations()
+	if err != nil {
+		fmt.Println("Ошибка при получении координат пользователей:", err)
+		return
+	}
+	fuzzed := 0
+	for userID, point := range points {
+		lat, lon, ok := geo.ParsePoint(point)
+		if !ok {
+			continue
+		}
+		rounded := geo.FormatPoint(geo.Fuzz(lat, lon, h.config.GeoPrecisionKm))
+		if rounded == point {
+			continue
+		}
+		if err := h.userRepo.UpdateUserGeo(userID, rounded); err != nil {
+			fmt.Println("Ошибка при сохранении координат:", err)
+			continue
+		}
+		fuzzed++
+	}
+	if fuzzed > 0 {
+		fmt.Printf("Координаты огрублены для %d пользователей\n", fuzzed)
+	}
+}
+
+// distanceText возвращает приблизительное расстояние до собеседника на языке viewer
+// или пустую строку, если у кого-то из них нет координат.
+func distanceText(viewer, other *repository.User) string {
+	distance, ok := repository.UserDistance(viewer, other)
+	if !ok {
+		return ""
+	}
+	return distance.Format(viewer.LanguageCode)
+}
+
+// connectText дополняет сообщение о подключении расстоянием до собеседника.
+func (h *Handler) connectText(userID, partnerID int64) string {
+	text := fmt.Sprintf("Вы подключены к собеседнику с ID: %d", partnerID)
+	user, err := h.userRepo.GetUser(userID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return text
+	}
+	partner, err := h.userRepo.GetUser(partnerID)
+	if err != nil {
+		fmt.Println("Ошибка получения пользователя:", err)
+		return text
+	}
+	if distance := distanceText(user, partner); distance != "" {
+		text += "\n📍 " + distance
+	}
+	return text
+}
+
+// formatCityStats выводит самые частые города по убыванию числа пользователей.
+// Пользователи без города и города за пределами top складываются в отдельные группы.
+func formatCityStats(counts map[string]int, top int) string {
+	cities := make([]string, 0, len(counts))
+	for city := range counts {
+		if city != "" {
+			cities = append(cities, city)
+		}
+	}
+	sort.Slice(cities, func(i, j int) bool {
+		if counts[cities[i]] != counts[cities[j]] {
+			return counts[cities[i]] > counts[cities[j]]
+		}
+		return cities[i] < cities[j]
+	})
+
+	var parts []string
+	other := 0
+	for i, city := range cities {
+		if i < top {
+			parts = append(parts, fmt.Sprintf("%s=%d", city, counts[city]))
+		} else {
+			other += counts[city]
+		}
+	}
+	if other > 0 {
+		parts = append(parts, fmt.Sprintf("басқа=%d", other))
+	}
+	if counts[""] > 0 {
+		parts = append(parts, fmt.Sprintf("белгісіз=%d", counts[""]))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// LogCityStats периодически выводит, сколько пользователей в каких городах.
+func (h *Handler) LogCityStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			counts, err := h.userRepo.CityCounts()
+			if err != nil {
+				fmt.Println("Ошибка при подсчёте пользователей по городам:", err)
+				continue
+			}
+			if stats := formatCityStats(counts, cityStatsTop); stats != "" {
+				fmt.Println("[CITIES]", stats)
+			}
+		}
+	}
+}
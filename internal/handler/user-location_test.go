@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCityStats(t *testing.T) {
+	counts := map[string]int{"Алматы": 5, "Астана": 3, "Шымкент": 3, "Тараз": 1, "": 2}
+	assert.Equal(t, "Алматы=5 | Астана=3 | Шымкент=3 | басқа=1 | белгісіз=2", formatCityStats(counts, 3))
+	assert.Equal(t, "", formatCityStats(map[string]int{}, 3))
+}
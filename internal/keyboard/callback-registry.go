@@ -0,0 +1,260 @@
+package keyboard
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxCallbackDataLen — ограничение Telegram на размер callback data.
+const maxCallbackDataLen = 64
+
+// ErrCallbackExpired возвращается, если payload кнопки не найден: истёк, уже использован или подделан.
+var ErrCallbackExpired = errors.New("callback expired")
+
+// CallbackStore хранит payload кнопок на стороне сервера.
+type CallbackStore interface {
+	Put(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Take возвращает payload и сразу удаляет его (для одноразовых кнопок).
+	Take(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// CallbackRegistry выдаёт короткие ключи для callback data и хранит по ним payload кнопок.
+type CallbackRegistry struct {
+	store CallbackStore
+}
+
+func NewCallbackRegistry(store CallbackStore) *CallbackRegistry {
+	return &CallbackRegistry{store: store}
+}
+
+// Callback — типизированный вид кнопок с общим префиксом callback data.
+type Callback[T any] struct {
+	registry *CallbackRegistry
+	prefix   string
+	ttl      time.Duration
+	oneTime  bool
+}
+
+// CallbackOption настраивает Callback.
+type CallbackOption func(*callbackOptions)
+
+type callbackOptions struct {
+	ttl     time.Duration
+	oneTime bool
+}
+
+// WithTTL задаёт срок жизни payload; по умолчанию он хранится бессрочно.
+func WithTTL(ttl time.Duration) CallbackOption {
+	return func(o *callbackOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithOneTime делает кнопку одноразовой: payload удаляется при первом Decode.
+func WithOneTime() CallbackOption {
+	return func(o *callbackOptions) {
+		o.oneTime = true
+	}
+}
+
+// NewCallback регистрирует вид кнопок с префиксом prefix (например, "delete_").
+// Тот же префикс используется в bot.WithCallbackQueryDataHandler.
+func NewCallback[T any](registry *CallbackRegistry, prefix string, opts ...CallbackOption) *Callback[T] {
+	var o callbackOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Callback[T]{
+		registry: registry,
+		prefix:   prefix,
+		ttl:      o.ttl,
+		oneTime:  o.oneTime,
+	}
+}
+
+// Prefix возвращает префикс callback data этого вида кнопок.
+func (c *Callback[T]) Prefix() string {
+	return c.prefix
+}
+
+// Button сохраняет payload и возвращает кнопку с коротким ключом в callback data.
+func (c *Callback[T]) Button(ctx context.Context, text string, payload T) (models.InlineKeyboardButton, error) {
+	data, err := c.Data(ctx, payload)
+	if err != nil {
+		return models.InlineKeyboardButton{}, err
+	}
+	return NewInlineButton(text, data), nil
+}
+
+// Data сохраняет payload и возвращает callback data вида <prefix><key>.
+func (c *Callback[T]) Data(ctx context.Context, payload T) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback payload: %w", err)
+	}
+	key, err := newCallbackKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate callback key: %w", err)
+	}
+	data := c.prefix + key
+	if len(data) > maxCallbackDataLen {
+		return "", fmt.Errorf("callback data %q is longer than %d bytes", data, maxCallbackDataLen)
+	}
+	if err := c.registry.store.Put(ctx, data, raw, c.ttl); err != nil {
+		return "", fmt.Errorf("failed to store callback payload: %w", err)
+	}
+	return data, nil
+}
+
+// Decode возвращает payload по callback data. Одноразовые кнопки после этого становятся недействительными.
+func (c *Callback[T]) Decode(ctx context.Context, data string) (*T, error) {
+	if !strings.HasPrefix(data, c.prefix) {
+		return nil, ErrCallbackExpired
+	}
+
+	var (
+		raw []byte
+		err error
+	)
+	if c.oneTime {
+		raw, err = c.registry.store.Take(ctx, data)
+	} else {
+		raw, err = c.registry.store.Get(ctx, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var payload T
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal callback payload: %w", err)
+	}
+	return &payload, nil
+}
+
+// Forget удаляет payload, например после успешного действия многоразовой кнопки.
+func (c *Callback[T]) Forget(ctx context.Context, data string) error {
+	return c.registry.store.Delete(ctx, data)
+}
+
+// newCallbackKey генерирует случайный ключ из 16 hex-символов.
+func newCallbackKey() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// RedisCallbackStore хранит payload кнопок в Redis под ключами callback:<data>.
+type RedisCallbackStore struct {
+	client *redis.Client
+}
+
+func NewRedisCallbackStore(client *redis.Client) *RedisCallbackStore {
+	return &RedisCallbackStore{client: client}
+}
+
+func (s *RedisCallbackStore) Put(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, "callback:"+key, data, ttl).Err()
+}
+
+func (s *RedisCallbackStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.Get(ctx, "callback:"+key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrCallbackExpired
+	}
+	return data, err
+}
+
+func (s *RedisCallbackStore) Take(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.GetDel(ctx, "callback:"+key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrCallbackExpired
+	}
+	return data, err
+}
+
+func (s *RedisCallbackStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, "callback:"+key).Err()
+}
+
+// MemoryCallbackStore хранит payload кнопок в памяти процесса (для тестов и запуска без Redis).
+type MemoryCallbackStore struct {
+	mu    sync.Mutex
+	items map[string]memoryCallbackItem
+	now   func() time.Time
+}
+
+type memoryCallbackItem struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewMemoryCallbackStore() *MemoryCallbackStore {
+	return &MemoryCallbackStore{
+		items: make(map[string]memoryCallbackItem),
+		now:   time.Now,
+	}
+}
+
+func (s *MemoryCallbackStore) Put(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := memoryCallbackItem{data: data}
+	if ttl > 0 {
+		item.expiresAt = s.now().Add(ttl)
+	}
+	s.items[key] = item
+	return nil
+}
+
+func (s *MemoryCallbackStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lookup(key)
+}
+
+func (s *MemoryCallbackStore) Take(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.lookup(key)
+	delete(s.items, key)
+	return data, err
+}
+
+func (s *MemoryCallbackStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+	return nil
+}
+
+// lookup возвращает живой payload; вызывается под мьютексом.
+func (s *MemoryCallbackStore) lookup(key string) ([]byte, error) {
+	item, ok := s.items[key]
+	if !ok {
+		return nil, ErrCallbackExpired
+	}
+	if !item.expiresAt.IsZero() && s.now().After(item.expiresAt) {
+		delete(s.items, key)
+		return nil, ErrCallbackExpired
+	}
+	return item.data, nil
+}
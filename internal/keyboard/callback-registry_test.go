@@ -0,0 +1,80 @@
+package keyboard
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPayload struct {
+	UserID int64  `json:"user_id"`
+	Note   string `json:"note"`
+}
+
+func TestCallback_ButtonAndDecode(t *testing.T) {
+	registry := NewCallbackRegistry(NewMemoryCallbackStore())
+	ctx := context.Background()
+	cb := NewCallback[testPayload](registry, "card_")
+
+	button, err := cb.Button(ctx, "Profile", testPayload{UserID: 123, Note: strings.Repeat("x", 200)})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(button.CallbackData, "card_"))
+	assert.LessOrEqual(t, len(button.CallbackData), maxCallbackDataLen)
+
+	payload, err := cb.Decode(ctx, button.CallbackData)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123), payload.UserID)
+
+	// Многоразовая кнопка читается повторно, пока её не забудут.
+	_, err = cb.Decode(ctx, button.CallbackData)
+	assert.NoError(t, err)
+	assert.NoError(t, cb.Forget(ctx, button.CallbackData))
+	_, err = cb.Decode(ctx, button.CallbackData)
+	assert.ErrorIs(t, err, ErrCallbackExpired)
+}
+
+func TestCallback_OneTime(t *testing.T) {
+	registry := NewCallbackRegistry(NewMemoryCallbackStore())
+	ctx := context.Background()
+	cb := NewCallback[testPayload](registry, "once_", WithOneTime())
+
+	data, err := cb.Data(ctx, testPayload{UserID: 1})
+	assert.NoError(t, err)
+
+	_, err = cb.Decode(ctx, data)
+	assert.NoError(t, err)
+	_, err = cb.Decode(ctx, data)
+	assert.ErrorIs(t, err, ErrCallbackExpired)
+}
+
+func TestCallback_Expiry(t *testing.T) {
+	store := NewMemoryCallbackStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	registry := NewCallbackRegistry(store)
+	ctx := context.Background()
+	cb := NewCallback[testPayload](registry, "ttl_", WithTTL(time.Minute))
+
+	data, err := cb.Data(ctx, testPayload{UserID: 1})
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = cb.Decode(ctx, data)
+	assert.ErrorIs(t, err, ErrCallbackExpired)
+}
+
+func TestCallback_ForeignPrefix(t *testing.T) {
+	registry := NewCallbackRegistry(NewMemoryCallbackStore())
+	ctx := context.Background()
+	cards := NewCallback[testPayload](registry, "card_")
+	deletes := NewCallback[testPayload](registry, "delete_")
+
+	data, err := cards.Data(ctx, testPayload{UserID: 1})
+	assert.NoError(t, err)
+
+	_, err = deletes.Decode(ctx, data)
+	assert.ErrorIs(t, err, ErrCallbackExpired)
+}
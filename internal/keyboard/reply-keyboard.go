@@ -0,0 +1,39 @@
+package keyboard
+
+import "github.com/go-telegram/bot/models"
+
+// ReplyKeyboard — клавиатура под полем ввода; нажатие кнопки отправляет её текст сообщением.
+type ReplyKeyboard struct {
+	rows [][]models.KeyboardButton
+}
+
+func NewReplyKeyboard() *ReplyKeyboard {
+	return &ReplyKeyboard{
+		rows: make([][]models.KeyboardButton, 0),
+	}
+}
+
+func (k *ReplyKeyboard) AddRow(buttons ...models.KeyboardButton) {
+	k.rows = append(k.rows, buttons)
+}
+
+func (k *ReplyKeyboard) Build() *models.ReplyKeyboardMarkup {
+	return &models.ReplyKeyboardMarkup{
+		Keyboard:       k.rows,
+		ResizeKeyboard: true,
+	}
+}
+
+func NewReplyButton(text string) models.KeyboardButton {
+	return models.KeyboardButton{Text: text}
+}
+
+// NewLocationButton создаёт кнопку, которая отправляет боту геолокацию пользователя.
+func NewLocationButton(text string) models.KeyboardButton {
+	return models.KeyboardButton{Text: text, RequestLocation: true}
+}
+
+// RemoveReplyKeyboard убирает ранее показанную клавиатуру.
+func RemoveReplyKeyboard() *models.ReplyKeyboardRemove {
+	return &models.ReplyKeyboardRemove{RemoveKeyboard: true}
+}
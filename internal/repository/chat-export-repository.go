@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// exportKey — метка последней выгрузки /mydata; пока она жива, новая выгрузка недоступна.
+const exportKey = "chat:export:%d"
+
+// TryStartExport разрешает выгрузку данных не чаще одного раза за cooldown.
+// Возвращает false и оставшееся время, если выгрузка уже была недавно.
+func (r *ChatRepository) TryStartExport(ctx context.Context, userID int64, cooldown time.Duration) (bool, time.Duration, error) {
+	key := fmt.Sprintf(exportKey, userID)
+	ok, err := r.client.SetNX(ctx, key, time.Now().Unix(), cooldown).Result()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to start export: %w", err)
+	}
+	if ok {
+		return true, 0, nil
+	}
+	left, err := r.client.TTL(ctx, key).Result()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get export cooldown: %w", err)
+	}
+	return false, left, nil
+}
+
+// CancelExport снимает ограничение, если выгрузка не удалась.
+func (r *ChatRepository) CancelExport(ctx context.Context, userID int64) error {
+	if err := r.client.Del(ctx, fmt.Sprintf(exportKey, userID)).Err(); err != nil {
+		return fmt.Errorf("failed to cancel export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// outboxTTL — сколько хранятся недоставленные сообщения сессии.
+const outboxTTL = 48 * time.Hour
+
+// outboxDueKey — ZSET сессий с недоставленными сообщениями, score — время следующей попытки.
+const outboxDueKey = "chat:outbox:due"
+
+// OutboxEntry — сообщение, ожидающее повторной доставки собеседнику.
+type OutboxEntry struct {
+	SenderID  int64  `json:"sender_id"`
+	PartnerID int64  `json:"partner_id"`
+	Attempts  int    `json:"attempts"`
+	Message   []byte `json:"message"`
+}
+
+// finishOutboxScript снимает сессию с расписания, только если её очередь действительно пуста:
+// между проверкой и удалением в очередь могло попасть новое сообщение.
+var finishOutboxScript = redis.NewScript(`
+if redis.call("LLEN", KEYS[1]) == 0 then
+	return redis.call("ZREM", KEYS[2], ARGV[1])
+end
+return 0
+`)
+
+// PushOutbox добавляет сообщение в конец очереди сессии и ставит сессию в расписание,
+// если её там ещё нет.
+func (r *ChatRepository) PushOutbox(ctx context.Context, sessionID string, entry *OutboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+	key := fmt.Sprintf("chat:outbox:%s", sessionID)
+	pipe := r.client.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.Expire(ctx, key, outboxTTL)
+	pipe.ZAddNX(ctx, outboxDueKey, redis.Z{Score: float64(time.Now().Unix()), Member: sessionID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to push outbox entry: %w", err)
+	}
+	return nil
+}
+
+// OutboxLen возвращает число недоставленных сообщений сессии.
+func (r *ChatRepository) OutboxLen(ctx context.Context, sessionID string) (int64, error) {
+	n, err := r.client.LLen(ctx, fmt.Sprintf("chat:outbox:%s", sessionID)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get outbox length: %w", err)
+	}
+	return n, nil
+}
+
+// PeekOutbox возвращает самое старое недоставленное сообщение сессии или nil.
+func (r *ChatRepository) PeekOutbox(ctx context.Context, sessionID string) (*OutboxEntry, error) {
+	data, err := r.client.LIndex(ctx, fmt.Sprintf("chat:outbox:%s", sessionID), 0).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to peek outbox: %w", err)
+	}
+	var entry OutboxEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal outbox entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// PopOutbox удаляет самое старое сообщение очереди (доставлено или брошено).
+func (r *ChatRepository) PopOutbox(ctx context.Context, sessionID string) error {
+	if err := r.client.LPop(ctx, fmt.Sprintf("chat:outbox:%s", sessionID)).Err(); err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to pop outbox: %w", err)
+	}
+	return nil
+}
+
+// RetryOutboxLater сохраняет число попыток для первого сообщения и переносит следующую попытку на nextAt.
+func (r *ChatRepository) RetryOutboxLater(ctx context.Context, sessionID string, entry *OutboxEntry, nextAt time.Time) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+	pipe := r.client.TxPipeline()
+	pipe.LSet(ctx, fmt.Sprintf("chat:outbox:%s", sessionID), 0, data)
+	pipe.ZAdd(ctx, outboxDueKey, redis.Z{Score: float64(nextAt.Unix()), Member: sessionID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to reschedule outbox: %w", err)
+	}
+	return nil
+}
+
+// ClearOutbox удаляет все недоставленные сообщения сессии.
+func (r *ChatRepository) ClearOutbox(ctx context.Context, sessionID string) error {
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf("chat:outbox:%s", sessionID))
+	pipe.ZRem(ctx, outboxDueKey, sessionID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to clear outbox: %w", err)
+	}
+	return nil
+}
+
+// FinishOutbox снимает сессию с расписания, если её очередь пуста.
+func (r *ChatRepository) FinishOutbox(ctx context.Context, sessionID string) error {
+	key := fmt.Sprintf("chat:outbox:%s", sessionID)
+	if err := finishOutboxScript.Run(ctx, r.client, []string{key, outboxDueKey}, sessionID).Err(); err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to finish outbox: %w", err)
+	}
+	return nil
+}
+
+// DueOutboxSessions возвращает сессии, для которых пора повторить доставку.
+func (r *ChatRepository) DueOutboxSessions(ctx context.Context, now time.Time, limit int64) ([]string, error) {
+	sessions, err := r.client.ZRangeByScore(ctx, outboxDueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("%d", now.Unix()),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due outboxes: %w", err)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// pollGroupTTL — сколько храним связь между копиями опроса и голоса.
+const pollGroupTTL = 7 * 24 * time.Hour
+
+// PollCopy — копия опроса, отправленная ботом в чат одного из собеседников.
+type PollCopy struct {
+	ChatID      int64  `json:"chat_id"`
+	MessageID   int    `json:"message_id"`
+	PollID      string `json:"poll_id"`
+	ResultMsgID int    `json:"result_msg_id"`
+}
+
+// PollGroup связывает копии одного опроса, голоса в которых считаются вместе.
+type PollGroup struct {
+	ID        string     `json:"id"`
+	Options   []string   `json:"options"`
+	Anonymous bool       `json:"anonymous"`
+	Copies    []PollCopy `json:"copies"`
+}
+
+// SavePollGroup сохраняет группу и индекс poll_id -> группа для каждой копии.
+func (r *ChatRepository) SavePollGroup(ctx context.Context, group *PollGroup) error {
+	if group.ID == "" {
+		id, err := newToken()
+		if err != nil {
+			return fmt.Errorf("failed to generate poll group id: %w", err)
+		}
+		group.ID = id
+	}
+	data, err := json.Marshal(group)
+	if err != nil {
+		return fmt.Errorf("failed to marshal poll group: %w", err)
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("chat:poll_group:%s", group.ID), data, pollGroupTTL)
+	for _, c := range group.Copies {
+		pipe.Set(ctx, fmt.Sprintf("chat:poll:%s", c.PollID), group.ID, pollGroupTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save poll group: %w", err)
+	}
+	return nil
+}
+
+// GetPollGroupByPoll возвращает группу, в которую входит опрос, или nil.
+func (r *ChatRepository) GetPollGroupByPoll(ctx context.Context, pollID string) (*PollGroup, error) {
+	groupID, err := r.client.Get(ctx, fmt.Sprintf("chat:poll:%s", pollID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get poll group id: %w", err)
+	}
+
+	data, err := r.client.Get(ctx, fmt.Sprintf("chat:poll_group:%s", groupID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get poll group: %w", err)
+	}
+	var group PollGroup
+	if err := json.Unmarshal(data, &group); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal poll group: %w", err)
+	}
+	return &group, nil
+}
+
+// SetPollVote запоминает выбор пользователя (из poll_answer); пустой выбор — отзыв голоса.
+func (r *ChatRepository) SetPollVote(ctx context.Context, groupID string, userID int64, optionIDs []int) error {
+	key := fmt.Sprintf("chat:poll_group:%s:votes", groupID)
+	field := fmt.Sprintf("%d", userID)
+	if len(optionIDs) == 0 {
+		if err := r.client.HDel(ctx, key, field).Err(); err != nil {
+			return fmt.Errorf("failed to retract poll vote: %w", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(optionIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal poll vote: %w", err)
+	}
+	if err := r.client.HSet(ctx, key, field, data).Err(); err != nil {
+		return fmt.Errorf("failed to set poll vote: %w", err)
+	}
+	r.client.Expire(ctx, key, pollGroupTTL)
+	return nil
+}
+
+// SetPollCounts запоминает счётчики одной копии анонимного опроса (из обновления poll).
+func (r *ChatRepository) SetPollCounts(ctx context.Context, groupID, pollID string, counts []int) error {
+	key := fmt.Sprintf("chat:poll_group:%s:counts", groupID)
+	data, err := json.Marshal(counts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal poll counts: %w", err)
+	}
+	if err := r.client.HSet(ctx, key, pollID, data).Err(); err != nil {
+		return fmt.Errorf("failed to set poll counts: %w", err)
+	}
+	r.client.Expire(ctx, key, pollGroupTTL)
+	return nil
+}
+
+// PollTally возвращает общее число голосов за каждый вариант по всем копиям.
+// Для открытых опросов считаем голоса из poll_answer, для анонимных — сумму счётчиков копий.
+func (r *ChatRepository) PollTally(ctx context.Context, group *PollGroup) ([]int, error) {
+	key := fmt.Sprintf("chat:poll_group:%s:votes", group.ID)
+	if group.Anonymous {
+		key = fmt.Sprintf("chat:poll_group:%s:counts", group.ID)
+	}
+	values, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll votes: %w", err)
+	}
+
+	tally := make([]int, len(group.Options))
+	for _, v := range values {
+		var numbers []int
+		if err := json.Unmarshal([]byte(v), &numbers); err != nil {
+			continue
+		}
+		for i, n := range numbers {
+			if group.Anonymous {
+				// numbers — счётчики по вариантам.
+				if i < len(tally) {
+					tally[i] += n
+				}
+			} else if n >= 0 && n < len(tally) {
+				// numbers — выбранные варианты.
+				tally[n]++
+			}
+		}
+	}
+	return tally, nil
+}
+
+// GetUserPollVotes возвращает голоса пользователя во всех опросах: ID группы -> выбранные варианты.
+func (r *ChatRepository) GetUserPollVotes(ctx context.Context, userID int64) (map[string][]int, error) {
+	field := fmt.Sprintf("%d", userID)
+	votes := make(map[string][]int)
+	iter := r.client.Scan(ctx, 0, "chat:poll_group:*:votes", 100).Iterator()
+	for iter.Next(ctx) {
+		data, err := r.client.HGet(ctx, iter.Val(), field).Bytes()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get poll vote: %w", err)
+		}
+		var optionIDs []int
+		if err := json.Unmarshal(data, &optionIDs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal poll vote: %w", err)
+		}
+		groupID := strings.TrimSuffix(strings.TrimPrefix(iter.Val(), "chat:poll_group:"), ":votes")
+		votes[groupID] = optionIDs
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan poll votes: %w", err)
+	}
+	return votes, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PurgeUser удаляет из Redis всё, что хранится о пользователе: участие в чате, собеседника,
+// текущую и прошлую сессию, его сообщения в списках этих сессий, настройки, состояние регистрации
+// и /edit, live-локации и голоса в опросах. Общие данные сессии (настройки, копии в канале) остаются
+// собеседнику, а запланированные удаления — планировщику: обещанное удаление должно состояться.
+// Очередь доставки сессии очищается отдельно через ClearOutbox.
+func (r *ChatRepository) PurgeUser(ctx context.Context, userID int64) error {
+	if err := r.client.SRem(ctx, "chat:users", userID).Err(); err != nil {
+		return fmt.Errorf("failed to remove user from set: %w", err)
+	}
+
+	for _, key := range []string{fmt.Sprintf("chat:session:%d", userID), fmt.Sprintf("chat:last_session:%d", userID)} {
+		sessionID, err := r.client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+		if err := r.forgetSessionMessages(ctx, sessionID, userID); err != nil {
+			return err
+		}
+	}
+
+	keys := []string{
+		fmt.Sprintf("chat:partner:%d", userID),
+		fmt.Sprintf("chat:session:%d", userID),
+		fmt.Sprintf("chat:last_session:%d", userID),
+		fmt.Sprintf("chat:settings:%d", userID),
+		fmt.Sprintf("chat:registration:%d", userID),
+		fmt.Sprintf("chat:edit:%d", userID),
+		fmt.Sprintf(exportKey, userID),
+	}
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete user keys: %w", err)
+	}
+
+	if err := r.deleteMatching(ctx, fmt.Sprintf("chat:live:%d:*", userID)); err != nil {
+		return err
+	}
+
+	field := fmt.Sprintf("%d", userID)
+	iter := r.client.Scan(ctx, 0, "chat:poll_group:*:votes", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := r.client.HDel(ctx, iter.Val(), field).Err(); err != nil {
+			return fmt.Errorf("failed to delete poll vote: %w", err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan poll votes: %w", err)
+	}
+	return nil
+}
+
+// forgetSessionMessages убирает из списка сообщений сессии те, что лежат в чате пользователя.
+// Сообщения в чате собеседника остаются, чтобы он мог "сжечь" чат.
+func (r *ChatRepository) forgetSessionMessages(ctx context.Context, sessionID string, chatID int64) error {
+	key := fmt.Sprintf("chat:session:%s:messages", sessionID)
+	values, err := r.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get session messages: %w", err)
+	}
+	prefix := fmt.Sprintf("%d:", chatID)
+	for _, v := range values {
+		if !strings.HasPrefix(v, prefix) {
+			continue
+		}
+		if err := r.client.LRem(ctx, key, 0, v).Err(); err != nil {
+			return fmt.Errorf("failed to forget session message: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteMatching удаляет все ключи по шаблону SCAN.
+func (r *ChatRepository) deleteMatching(ctx context.Context, pattern string) error {
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		if err := r.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", iter.Val(), err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan %s: %w", pattern, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// registrationTTL — сколько помним незаконченную регистрацию.
+const registrationTTL = 30 * 24 * time.Hour
+
+// profileEditTTL — сколько бот ждёт новое значение поля после нажатия кнопки в /edit.
+const profileEditTTL = 10 * time.Minute
+
+// SetRegistrationStep запоминает шаг регистрации, на котором остановился пользователь.
+func (r *ChatRepository) SetRegistrationStep(ctx context.Context, userID int64, step string) error {
+	key := fmt.Sprintf("chat:registration:%d", userID)
+	if err := r.client.Set(ctx, key, step, registrationTTL).Err(); err != nil {
+		return fmt.Errorf("failed to set registration step: %w", err)
+	}
+	return nil
+}
+
+// GetRegistrationStep возвращает текущий шаг регистрации или пустую строку, если регистрация не начата.
+func (r *ChatRepository) GetRegistrationStep(ctx context.Context, userID int64) (string, error) {
+	key := fmt.Sprintf("chat:registration:%d", userID)
+	step, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get registration step: %w", err)
+	}
+	return step, nil
+}
+
+// ClearRegistrationStep удаляет состояние регистрации после её завершения.
+func (r *ChatRepository) ClearRegistrationStep(ctx context.Context, userID int64) error {
+	if err := r.client.Del(ctx, fmt.Sprintf("chat:registration:%d", userID)).Err(); err != nil {
+		return fmt.Errorf("failed to clear registration step: %w", err)
+	}
+	return nil
+}
+
+// SetEditField запоминает поле анкеты, новое значение которого пользователь пришлёт следующим сообщением.
+func (r *ChatRepository) SetEditField(ctx context.Context, userID int64, field string) error {
+	key := fmt.Sprintf("chat:edit:%d", userID)
+	if err := r.client.Set(ctx, key, field, profileEditTTL).Err(); err != nil {
+		return fmt.Errorf("failed to set edit field: %w", err)
+	}
+	return nil
+}
+
+// GetEditField возвращает редактируемое поле анкеты или пустую строку.
+func (r *ChatRepository) GetEditField(ctx context.Context, userID int64) (string, error) {
+	key := fmt.Sprintf("chat:edit:%d", userID)
+	field, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get edit field: %w", err)
+	}
+	return field, nil
+}
+
+// ClearEditField завершает редактирование поля анкеты.
+func (r *ChatRepository) ClearEditField(ctx context.Context, userID int64) error {
+	if err := r.client.Del(ctx, fmt.Sprintf("chat:edit:%d", userID)).Err(); err != nil {
+		return fmt.Errorf("failed to clear edit field: %w", err)
+	}
+	return nil
+}
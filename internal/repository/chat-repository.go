@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -83,6 +86,19 @@ func (r *ChatRepository) RemoveUser(ctx context.Context, userID int64) error {
 		return fmt.Errorf("failed to delete partner mapping: %w", err)
 	}
 
+	// Remove session id, remembering it for a while so the chat can still be burned
+	keySession := fmt.Sprintf("chat:session:%d", userID)
+	sessionID, err := r.client.GetDel(ctx, keySession).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if sessionID != "" {
+		keyLast := fmt.Sprintf("chat:last_session:%d", userID)
+		if err := r.client.Set(ctx, keyLast, sessionID, LastSessionTTL).Err(); err != nil {
+			return fmt.Errorf("failed to remember last session: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -109,6 +125,321 @@ func (r *ChatRepository) CheckPartnerToEmpty(ctx context.Context, userID int64)
 	return exists > 0, nil
 }
 
+// Названия пользовательских настроек чата, хранящихся в chat:settings:<userID>.
+const (
+	SettingTypingPulse = "typing_pulse"
+	SettingEcho        = "echo"
+)
+
+// SetUserSetting включает или выключает настройку чата для пользователя.
+func (r *ChatRepository) SetUserSetting(ctx context.Context, userID int64, name string, enabled bool) error {
+	key := fmt.Sprintf("chat:settings:%d", userID)
+	value := 0
+	if enabled {
+		value = 1
+	}
+	if err := r.client.HSet(ctx, key, name, value).Err(); err != nil {
+		return fmt.Errorf("failed to set user setting: %w", err)
+	}
+	return nil
+}
+
+// GetUserSetting возвращает значение настройки чата; отсутствующая настройка считается выключенной.
+func (r *ChatRepository) GetUserSetting(ctx context.Context, userID int64, name string) (bool, error) {
+	key := fmt.Sprintf("chat:settings:%d", userID)
+	value, err := r.client.HGet(ctx, key, name).Result()
+	if err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get user setting: %w", err)
+	}
+	return value == "1", nil
+}
+
+// GetUserSettings возвращает все сохранённые настройки чата пользователя.
+func (r *ChatRepository) GetUserSettings(ctx context.Context, userID int64) (map[string]bool, error) {
+	key := fmt.Sprintf("chat:settings:%d", userID)
+	values, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	settings := make(map[string]bool, len(values))
+	for name, value := range values {
+		settings[name] = value == "1"
+	}
+	return settings, nil
+}
+
+// StartSession создаёт идентификатор новой сессии и записывает его обоим собеседникам.
+func (r *ChatRepository) StartSession(ctx context.Context, userID, partnerID int64) (string, error) {
+	sessionID, err := newToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	for _, id := range []int64{userID, partnerID} {
+		key := fmt.Sprintf("chat:session:%d", id)
+		if err := r.client.Set(ctx, key, sessionID, 0).Err(); err != nil {
+			return "", fmt.Errorf("failed to set session: %w", err)
+		}
+	}
+	return sessionID, nil
+}
+
+// GetSession возвращает идентификатор текущей сессии пользователя или пустую строку.
+func (r *ChatRepository) GetSession(ctx context.Context, userID int64) (string, error) {
+	key := fmt.Sprintf("chat:session:%d", userID)
+	sessionID, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+	return sessionID, nil
+}
+
+// GetLastSession возвращает сессию, которую пользователь недавно завершил, или пустую строку.
+func (r *ChatRepository) GetLastSession(ctx context.Context, userID int64) (string, error) {
+	key := fmt.Sprintf("chat:last_session:%d", userID)
+	sessionID, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get last session: %w", err)
+	}
+	return sessionID, nil
+}
+
+// LastSessionTTL — сколько после выхода из чата ещё можно "сжечь" переписку.
+const LastSessionTTL = time.Hour
+
+// sessionMessagesTTL — дольше 48 часов Telegram всё равно не даёт удалять сообщения.
+const sessionMessagesTTL = 48 * time.Hour
+
+// TrackedMessage — сообщение сессии, которое можно удалить при "сжигании" чата.
+type TrackedMessage struct {
+	ChatID    int64
+	MessageID int
+}
+
+// TrackMessage запоминает сообщение, относящееся к сессии, в одном из чатов собеседников.
+func (r *ChatRepository) TrackMessage(ctx context.Context, sessionID string, chatID int64, messageID int) error {
+	key := fmt.Sprintf("chat:session:%s:messages", sessionID)
+	if err := r.client.RPush(ctx, key, fmt.Sprintf("%d:%d", chatID, messageID)).Err(); err != nil {
+		return fmt.Errorf("failed to track message: %w", err)
+	}
+	if err := r.client.Expire(ctx, key, sessionMessagesTTL).Err(); err != nil {
+		return fmt.Errorf("failed to set messages expiry: %w", err)
+	}
+	return nil
+}
+
+// TrackChannelMessage запоминает копию сообщения сессии в канале.
+func (r *ChatRepository) TrackChannelMessage(ctx context.Context, sessionID string, messageID int) error {
+	key := fmt.Sprintf("chat:session:%s:channel", sessionID)
+	if err := r.client.RPush(ctx, key, messageID).Err(); err != nil {
+		return fmt.Errorf("failed to track channel message: %w", err)
+	}
+	if err := r.client.Expire(ctx, key, sessionMessagesTTL).Err(); err != nil {
+		return fmt.Errorf("failed to set channel messages expiry: %w", err)
+	}
+	return nil
+}
+
+// TakeSessionMessages возвращает все сообщения сессии в чатах собеседников и забывает их.
+func (r *ChatRepository) TakeSessionMessages(ctx context.Context, sessionID string) ([]TrackedMessage, error) {
+	key := fmt.Sprintf("chat:session:%s:messages", sessionID)
+	values, err := r.takeList(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take session messages: %w", err)
+	}
+
+	var messages []TrackedMessage
+	for _, v := range values {
+		var m TrackedMessage
+		if _, err := fmt.Sscanf(v, "%d:%d", &m.ChatID, &m.MessageID); err != nil {
+			continue
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// TakeSessionChannelMessages возвращает копии сообщений сессии в канале и забывает их.
+func (r *ChatRepository) TakeSessionChannelMessages(ctx context.Context, sessionID string) ([]int, error) {
+	key := fmt.Sprintf("chat:session:%s:channel", sessionID)
+	values, err := r.takeList(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take channel messages: %w", err)
+	}
+
+	var ids []int
+	for _, v := range values {
+		ids = append(ids, int(parseInt64(v)))
+	}
+	return ids, nil
+}
+
+// takeList атомарно читает и удаляет список.
+func (r *ChatRepository) takeList(ctx context.Context, key string) ([]string, error) {
+	pipe := r.client.TxPipeline()
+	values := pipe.LRange(ctx, key, 0, -1)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return values.Val(), nil
+}
+
+// sessionSettingsTTL — настройки сессии живут не дольше недели.
+const sessionSettingsTTL = 7 * 24 * time.Hour
+
+// SetSessionEphemeral включает режим самоудаляющихся сообщений для сессии; ttl = 0 выключает его.
+func (r *ChatRepository) SetSessionEphemeral(ctx context.Context, sessionID string, ttl time.Duration) error {
+	key := fmt.Sprintf("chat:session:%s:settings", sessionID)
+	if err := r.client.HSet(ctx, key, "ephemeral_seconds", int64(ttl/time.Second)).Err(); err != nil {
+		return fmt.Errorf("failed to set ephemeral mode: %w", err)
+	}
+	if err := r.client.Expire(ctx, key, sessionSettingsTTL).Err(); err != nil {
+		return fmt.Errorf("failed to set session settings expiry: %w", err)
+	}
+	return nil
+}
+
+// GetSessionEphemeral возвращает время жизни сообщений сессии или 0, если режим выключен.
+func (r *ChatRepository) GetSessionEphemeral(ctx context.Context, sessionID string) (time.Duration, error) {
+	key := fmt.Sprintf("chat:session:%s:settings", sessionID)
+	value, err := r.client.HGet(ctx, key, "ephemeral_seconds").Result()
+	if err == redis.Nil {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to get ephemeral mode: %w", err)
+	}
+	return time.Duration(parseInt64(value)) * time.Second, nil
+}
+
+// ScheduleDeletion ставит сообщение в очередь на удаление в момент at.
+// Очередь хранится в Redis и переживает перезапуск бота.
+func (r *ChatRepository) ScheduleDeletion(ctx context.Context, chatID int64, messageID int, at time.Time) error {
+	key := "chat:deletions"
+	member := fmt.Sprintf("%d:%d", chatID, messageID)
+	if err := r.client.ZAdd(ctx, key, redis.Z{Score: float64(at.Unix()), Member: member}).Err(); err != nil {
+		return fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+	return nil
+}
+
+// DueDeletions возвращает сообщения, срок удаления которых наступил. Сообщения остаются
+// в очереди, пока их не уберёт CompleteDeletion, поэтому неудавшееся удаление не теряется.
+func (r *ChatRepository) DueDeletions(ctx context.Context, now time.Time, limit int64) ([]TrackedMessage, error) {
+	key := "chat:deletions"
+	members, err := r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("%d", now.Unix()),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due deletions: %w", err)
+	}
+
+	var messages []TrackedMessage
+	for _, member := range members {
+		var m TrackedMessage
+		if _, err := fmt.Sscanf(member, "%d:%d", &m.ChatID, &m.MessageID); err != nil {
+			continue
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// CompleteDeletion убирает сообщение из очереди удаления.
+func (r *ChatRepository) CompleteDeletion(ctx context.Context, chatID int64, messageID int) error {
+	member := fmt.Sprintf("%d:%d", chatID, messageID)
+	if err := r.client.ZRem(ctx, "chat:deletions", member).Err(); err != nil {
+		return fmt.Errorf("failed to complete deletion: %w", err)
+	}
+	return nil
+}
+
+// LiveLocation — копия live-локации у собеседника и сессия, в которой она отправлена.
+type LiveLocation struct {
+	TrackedMessage
+	SessionID string
+}
+
+// SetLiveLocation связывает live-локацию отправителя с её копией у собеседника.
+func (r *ChatRepository) SetLiveLocation(ctx context.Context, chatID int64, messageID int, live LiveLocation, ttl time.Duration) error {
+	key := fmt.Sprintf("chat:live:%d:%d", chatID, messageID)
+	value := fmt.Sprintf("%d:%d:%s", live.ChatID, live.MessageID, live.SessionID)
+	if err := r.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set live location: %w", err)
+	}
+	return nil
+}
+
+// GetLiveLocation возвращает копию live-локации у собеседника или nil, если связи нет.
+func (r *ChatRepository) GetLiveLocation(ctx context.Context, chatID int64, messageID int) (*LiveLocation, error) {
+	key := fmt.Sprintf("chat:live:%d:%d", chatID, messageID)
+	value, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get live location: %w", err)
+	}
+	return parseLiveLocation(value)
+}
+
+// TakeLiveLocations забирает все live-локации, которые транслирует пользователь.
+func (r *ChatRepository) TakeLiveLocations(ctx context.Context, chatID int64) ([]LiveLocation, error) {
+	pattern := fmt.Sprintf("chat:live:%d:*", chatID)
+	var locations []LiveLocation
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		value, err := r.client.GetDel(ctx, iter.Val()).Result()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			return locations, fmt.Errorf("failed to take live location: %w", err)
+		}
+		live, err := parseLiveLocation(value)
+		if err != nil {
+			continue
+		}
+		locations = append(locations, *live)
+	}
+	if err := iter.Err(); err != nil {
+		return locations, fmt.Errorf("failed to scan live locations: %w", err)
+	}
+	return locations, nil
+}
+
+// parseLiveLocation разбирает значение chat:live:* вида "chatID:messageID:sessionID".
+func parseLiveLocation(value string) (*LiveLocation, error) {
+	var live LiveLocation
+	if _, err := fmt.Sscanf(value, "%d:%d:%s", &live.ChatID, &live.MessageID, &live.SessionID); err != nil {
+		return nil, fmt.Errorf("failed to parse live location: %w", err)
+	}
+	return &live, nil
+}
+
+// RemoveLiveLocation забывает связь после остановки трансляции.
+func (r *ChatRepository) RemoveLiveLocation(ctx context.Context, chatID int64, messageID int) error {
+	key := fmt.Sprintf("chat:live:%d:%d", chatID, messageID)
+	if err := r.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to remove live location: %w", err)
+	}
+	return nil
+}
+
+// newToken генерирует случайный токен из 16 hex-символов.
+func newToken() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
 func parseInt64(s string) int64 {
 	var id int64
 	fmt.Sscanf(s, "%d", &id)
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -98,3 +100,240 @@ func TestChatRepository_GetUsers(t *testing.T) {
 
 	client.FlushDB(ctx)
 }
+
+func TestChatRepository_StartSession(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+
+	sessionID, err := repo.StartSession(ctx, 123, 456)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sessionID)
+
+	partnerSession, err := repo.GetSession(ctx, 456)
+	assert.NoError(t, err)
+	assert.Equal(t, sessionID, partnerSession)
+
+	repo.RemoveUser(ctx, 123)
+	userSession, err := repo.GetSession(ctx, 123)
+	assert.NoError(t, err)
+	assert.Empty(t, userSession)
+
+	client.FlushDB(ctx)
+}
+
+func TestChatRepository_PollTally(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+
+	group := &PollGroup{
+		Options: []string{"A", "B"},
+		Copies:  []PollCopy{{ChatID: 123, PollID: "p1"}, {ChatID: 456, PollID: "p2"}},
+	}
+	assert.NoError(t, repo.SavePollGroup(ctx, group))
+
+	found, err := repo.GetPollGroupByPoll(ctx, "p2")
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, group.ID, found.ID)
+	}
+
+	// Голоса из обеих копий складываются; повторный голос заменяет прежний.
+	repo.SetPollVote(ctx, group.ID, 123, []int{0})
+	repo.SetPollVote(ctx, group.ID, 456, []int{0})
+	repo.SetPollVote(ctx, group.ID, 456, []int{1})
+
+	tally, err := repo.PollTally(ctx, group)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 1}, tally)
+
+	client.FlushDB(ctx)
+}
+
+func TestChatRepository_Outbox(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.PushOutbox(ctx, "s1", &OutboxEntry{SenderID: 123, Message: []byte("first")}))
+	assert.NoError(t, repo.PushOutbox(ctx, "s1", &OutboxEntry{SenderID: 123, Message: []byte("second")}))
+
+	due, err := repo.DueOutboxSessions(ctx, time.Now(), 10)
+	assert.NoError(t, err)
+	assert.Contains(t, due, "s1")
+
+	// Неудачная попытка откладывает всю очередь сессии, порядок сохраняется.
+	entry, err := repo.PeekOutbox(ctx, "s1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, []byte("first"), entry.Message)
+		entry.Attempts++
+		assert.NoError(t, repo.RetryOutboxLater(ctx, "s1", entry, time.Now().Add(time.Minute)))
+	}
+	due, err = repo.DueOutboxSessions(ctx, time.Now(), 10)
+	assert.NoError(t, err)
+	assert.NotContains(t, due, "s1")
+
+	assert.NoError(t, repo.PopOutbox(ctx, "s1"))
+	entry, err = repo.PeekOutbox(ctx, "s1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, []byte("second"), entry.Message)
+	}
+
+	client.FlushDB(ctx)
+}
+
+func TestChatRepository_RegistrationStep(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+
+	step, err := repo.GetRegistrationStep(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, "", step)
+
+	assert.NoError(t, repo.SetRegistrationStep(ctx, 123, "age"))
+	step, err = repo.GetRegistrationStep(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, "age", step)
+
+	assert.NoError(t, repo.ClearRegistrationStep(ctx, 123))
+	step, err = repo.GetRegistrationStep(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, "", step)
+
+	client.FlushDB(ctx)
+}
+
+func TestChatRepository_PurgeUser(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.AddUser(ctx, 123))
+	assert.NoError(t, repo.AddUser(ctx, 456))
+	assert.NoError(t, repo.SetPartner(ctx, 123, 456))
+	_, err := repo.StartSession(ctx, 123, 456)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SetUserSetting(ctx, 123, SettingEcho, true))
+	assert.NoError(t, repo.SetLiveLocation(ctx, 123, 1, LiveLocation{TrackedMessage: TrackedMessage{ChatID: 456, MessageID: 2}, SessionID: "s"}, time.Minute))
+	assert.NoError(t, repo.SetPollVote(ctx, "group", 123, []int{0}))
+	assert.NoError(t, repo.SetPollVote(ctx, "group", 456, []int{1}))
+	sessionID, err := repo.GetSession(ctx, 123)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.TrackMessage(ctx, sessionID, 123, 10))
+	assert.NoError(t, repo.TrackMessage(ctx, sessionID, 456, 20))
+	assert.NoError(t, repo.SetSessionEphemeral(ctx, sessionID, time.Minute))
+	assert.NoError(t, repo.ScheduleDeletion(ctx, 123, 10, time.Now().Add(time.Minute)))
+	assert.NoError(t, repo.ScheduleDeletion(ctx, 456, 11, time.Now().Add(time.Minute)))
+
+	assert.NoError(t, repo.PurgeUser(ctx, 123))
+
+	users, err := repo.GetUsers(ctx)
+	assert.NoError(t, err)
+	assert.NotContains(t, users, int64(123))
+	assert.Contains(t, users, int64(456))
+
+	keys, err := client.Keys(ctx, "chat:*:123").Result()
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+	live, err := repo.GetLiveLocation(ctx, 123, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, live)
+	votes, err := client.HKeys(ctx, "chat:poll_group:group:votes").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"456"}, votes)
+
+	// Сообщения собеседника и общие настройки сессии остаются.
+	messages, err := client.LRange(ctx, fmt.Sprintf("chat:session:%s:messages", sessionID), 0, -1).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"456:20"}, messages)
+	ephemeral, err := repo.GetSessionEphemeral(ctx, sessionID)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, ephemeral)
+	// Обещанные удаления выполнит планировщик.
+	deletions, err := client.ZRange(ctx, "chat:deletions", 0, -1).Result()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"123:10", "456:11"}, deletions)
+
+	client.FlushDB(ctx)
+}
+
+func TestChatRepository_TryStartExport(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+
+	ok, _, err := repo.TryStartExport(ctx, 123, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, left, err := repo.TryStartExport(ctx, 123, time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.True(t, left > 0 && left <= time.Hour)
+
+	client.FlushDB(ctx)
+}
+
+func TestChatRepository_Deletions(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+	defer client.FlushDB(ctx)
+
+	now := time.Now()
+	assert.NoError(t, repo.ScheduleDeletion(ctx, 123, 10, now.Add(-time.Second)))
+	assert.NoError(t, repo.ScheduleDeletion(ctx, 456, 20, now.Add(time.Minute)))
+
+	due, err := repo.DueDeletions(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []TrackedMessage{{ChatID: 123, MessageID: 10}}, due)
+
+	// Пока удаление не подтверждено, сообщение остаётся в очереди.
+	due, err = repo.DueDeletions(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	// Повтор переносит срок, а не добавляет копию.
+	assert.NoError(t, repo.ScheduleDeletion(ctx, 123, 10, now.Add(10*time.Second)))
+	due, err = repo.DueDeletions(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	assert.NoError(t, repo.CompleteDeletion(ctx, 123, 10))
+	due, err = repo.DueDeletions(ctx, now.Add(time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []TrackedMessage{{ChatID: 456, MessageID: 20}}, due)
+}
+
+func TestChatRepository_LiveLocations(t *testing.T) {
+	client := setupTestRedisClient()
+	repo := NewRedisClient(client)
+	ctx := context.Background()
+	defer client.FlushDB(ctx)
+
+	first := LiveLocation{TrackedMessage: TrackedMessage{ChatID: 456, MessageID: 2}, SessionID: "abc"}
+	second := LiveLocation{TrackedMessage: TrackedMessage{ChatID: 456, MessageID: 4}, SessionID: "abc"}
+	assert.NoError(t, repo.SetLiveLocation(ctx, 123, 1, first, time.Minute))
+	assert.NoError(t, repo.SetLiveLocation(ctx, 123, 3, second, time.Minute))
+	assert.NoError(t, repo.SetLiveLocation(ctx, 456, 5, LiveLocation{TrackedMessage: TrackedMessage{ChatID: 123, MessageID: 6}, SessionID: "abc"}, time.Minute))
+
+	live, err := repo.GetLiveLocation(ctx, 123, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &first, live)
+
+	taken, err := repo.TakeLiveLocations(ctx, 123)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []LiveLocation{first, second}, taken)
+
+	// Связи пользователя забыты, трансляции собеседника остаются.
+	live, err = repo.GetLiveLocation(ctx, 123, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, live)
+	live, err = repo.GetLiveLocation(ctx, 456, 5)
+	assert.NoError(t, err)
+	assert.NotNil(t, live)
+}
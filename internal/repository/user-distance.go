@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"tanysu-bot/internal/geo"
+)
+
+// Ближе порога расстояние не уточняется, показывается только "ближе N км". Если город
+// выбран из списка, координаты — его центр, поэтому порог и шаг округления крупнее.
+const (
+	nearDistanceKm      = 5
+	nearCityDistanceKm  = 10
+	cityDistanceMinStep = 10
+)
+
+// distanceSteps — шаг округления в зависимости от расстояния. Чем дальше собеседник,
+// тем грубее число: по нескольким замерам нельзя вычислить его положение.
+var distanceSteps = []struct {
+	below float64
+	step  int
+}{
+	{50, 5},
+	{200, 10},
+	{1000, 50},
+	{math.Inf(1), 100},
+}
+
+// Distance — расстояние между двумя пользователями, огрублённое для показа.
+type Distance struct {
+	// Km — округлённое расстояние или, если Near, граница, ближе которой находится собеседник.
+	Km   int
+	Near bool
+	// SameCity — один из пользователей выбрал город вручную, и города совпадают.
+	SameCity bool
+}
+
+// UserDistance вычисляет расстояние по дуге большого круга между двумя пользователями
+// и огрубляет его. false — у кого-то из них нет координат.
+func UserDistance(a, b *User) (Distance, bool) {
+	latA, lonA, okA := geo.ParsePoint(a.UserGeo)
+	latB, lonB, okB := geo.ParsePoint(b.UserGeo)
+	if !okA || !okB {
+		return Distance{}, false
+	}
+	approx := a.GeoApprox || b.GeoApprox
+	if approx && a.UserCity != "" && a.UserCity == b.UserCity {
+		return Distance{SameCity: true}, true
+	}
+	return bucketDistance(geo.Distance(latA, lonA, latB, lonB), approx), true
+}
+
+// bucketDistance округляет расстояние до шага из distanceSteps. approx — хотя бы одна точка
+// известна с точностью до города.
+func bucketDistance(km float64, approx bool) Distance {
+	near := nearDistanceKm
+	if approx {
+		near = nearCityDistanceKm
+	}
+	if km < float64(near) {
+		return Distance{Km: near, Near: true}
+	}
+	step := 0
+	for _, s := range distanceSteps {
+		if km < s.below {
+			step = s.step
+			break
+		}
+	}
+	if approx {
+		step = max(step, cityDistanceMinStep)
+	}
+	rounded := int(math.Round(km/float64(step))) * step
+	return Distance{Km: max(rounded, near)}
+}
+
+// distanceTexts — формулировки расстояния на поддерживаемых языках.
+var distanceTexts = map[string]struct{ about, near, sameCity string }{
+	"kk": {"Сізден ≈ %d км", "Сізге %d км-ден жақын", "Сізбен бір қалада"},
+	"ru": {"≈ %d км от вас", "Ближе %d км от вас", "В одном городе с вами"},
+	"en": {"≈ %d km from you", "Less than %d km from you", "In the same city as you"},
+}
+
+// distanceLanguage выбирает язык по language_code из Telegram; по умолчанию — казахский.
+func distanceLanguage(languageCode string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if _, ok := distanceTexts[lang]; ok {
+		return lang
+	}
+	return "kk"
+}
+
+// Format возвращает расстояние на языке пользователя.
+func (d Distance) Format(languageCode string) string {
+	texts := distanceTexts[distanceLanguage(languageCode)]
+	switch {
+	case d.SameCity:
+		return texts.sameCity
+	case d.Near:
+		return fmt.Sprintf(texts.near, d.Km)
+	default:
+		return fmt.Sprintf(texts.about, d.Km)
+	}
+}
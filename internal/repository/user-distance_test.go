@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDistance(t *testing.T) {
+	almaty := &User{UserGeo: "43.23890,76.88970", UserCity: "Алматы"}
+	astana := &User{UserGeo: "51.16940,71.44910", UserCity: "Астана"}
+	nearby := &User{UserGeo: "43.26000,76.92000", UserCity: "Алматы"}
+
+	// Алматы — Астана около 970 км: округляется до 50.
+	d, ok := UserDistance(almaty, astana)
+	assert.True(t, ok)
+	assert.Equal(t, Distance{Km: 950}, d)
+
+	// Пара километров внутри города не уточняется.
+	d, ok = UserDistance(almaty, nearby)
+	assert.True(t, ok)
+	assert.Equal(t, Distance{Km: nearDistanceKm, Near: true}, d)
+
+	// Город выбран вручную — показываем только, что город тот же.
+	picked := &User{UserGeo: "43.23890,76.88970", UserCity: "Алматы", GeoApprox: true}
+	d, ok = UserDistance(nearby, picked)
+	assert.True(t, ok)
+	assert.Equal(t, Distance{SameCity: true}, d)
+
+	_, ok = UserDistance(almaty, &User{})
+	assert.False(t, ok)
+}
+
+func TestBucketDistance(t *testing.T) {
+	assert.Equal(t, Distance{Km: 5, Near: true}, bucketDistance(0.3, false))
+	assert.Equal(t, Distance{Km: 10}, bucketDistance(11.9, false))
+	assert.Equal(t, Distance{Km: 120}, bucketDistance(123, false))
+	assert.Equal(t, Distance{Km: 1300}, bucketDistance(1260, false))
+
+	// Для города из списка и порог, и шаг крупнее.
+	assert.Equal(t, Distance{Km: 10, Near: true}, bucketDistance(7, true))
+	assert.Equal(t, Distance{Km: 30}, bucketDistance(27, true))
+}
+
+func TestDistance_Format(t *testing.T) {
+	assert.Equal(t, "Сізден ≈ 12 км", Distance{Km: 12}.Format("kk"))
+	assert.Equal(t, "≈ 12 км от вас", Distance{Km: 12}.Format("ru"))
+	assert.Equal(t, "≈ 12 km from you", Distance{Km: 12}.Format("en-GB"))
+	assert.Equal(t, "Сізге 5 км-ден жақын", Distance{Km: 5, Near: true}.Format(""))
+	assert.Equal(t, "In the same city as you", Distance{SameCity: true}.Format("EN"))
+	// Неподдерживаемый язык — казахский по умолчанию.
+	assert.Equal(t, "Сізбен бір қалада", Distance{SameCity: true}.Format("de"))
+}
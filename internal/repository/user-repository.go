@@ -15,9 +15,13 @@ type User struct {
 	UserAge      int    // Жас (кейін толтырылады)
 	UserSex      string // Жыныс (кейін толтырылады)
 	UserGeo      string // Геолокация (кейін толтырылады)
+	UserCity     string // Геолокация бойынша анықталған қала
+	GeoApprox    bool   // Геолокация дәл емес: қолданушы қаланы тізімнен таңдаған
 	FirstName    string // Telegram-дағы аты
 	LastName     string // Telegram-дағы тегі
 	Contact      string // Байланыс (бар болса)
+	Bio          string // Өзі туралы қысқаша мәтін
+	LanguageCode string // Telegram клиентінің тілі (мысалы, "kk", "ru")
 }
 
 // UserRepository пайдаланушы деректерін БД-мен жұмыс істейді.
@@ -54,8 +58,12 @@ func (r *UserRepository) InsertUser(user *User) error {
 			user_geo,
 			first_name,
 			last_name,
-			contact
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			contact,
+			bio,
+			user_city,
+			geo_approx,
+			language_code
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := r.db.Exec(query,
 		user.UserID,
@@ -69,6 +77,10 @@ func (r *UserRepository) InsertUser(user *User) error {
 		user.FirstName,
 		user.LastName,
 		user.Contact,
+		user.Bio,
+		user.UserCity,
+		user.GeoApprox,
+		user.LanguageCode,
 	)
 	if err != nil {
 		return fmt.Errorf("InsertUser қатесі: %w", err)
@@ -79,7 +91,7 @@ func (r *UserRepository) InsertUser(user *User) error {
 // GetUser userID бойынша қолданушыны қайтарады.
 func (r *UserRepository) GetUser(userID int64) (*User, error) {
 	query := `
-		SELECT user_id, ava, ava_file_id, user_nickname, user_name, user_age, user_sex, user_geo, first_name, last_name, contact
+		SELECT user_id, ava, ava_file_id, user_nickname, user_name, user_age, user_sex, user_geo, first_name, last_name, contact, bio, user_city, geo_approx, language_code
 		FROM users WHERE user_id = ?
 	`
 	var user User
@@ -95,6 +107,10 @@ func (r *UserRepository) GetUser(userID int64) (*User, error) {
 		&user.FirstName,
 		&user.LastName,
 		&user.Contact,
+		&user.Bio,
+		&user.UserCity,
+		&user.GeoApprox,
+		&user.LanguageCode,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("GetUser қатесі: %w", err)
@@ -151,3 +167,137 @@ func (r *UserRepository) UpdateUserGeo(userID int64, geo string) error {
 	}
 	return nil
 }
+
+// UpdateUserLocation қолданушының геолокациясын, қаласын және дәлдік белгісін бірге жаңартады.
+func (r *UserRepository) UpdateUserLocation(userID int64, geo, city string, approx bool) error {
+	query := `UPDATE users SET user_geo = ?, user_city = ?, geo_approx = ? WHERE user_id = ?`
+	_, err := r.db.Exec(query, geo, city, approx, userID)
+	if err != nil {
+		return fmt.Errorf("UpdateUserLocation қатесі: %w", err)
+	}
+	return nil
+}
+
+// UpdateUserCity қолданушының қаласын жаңартады.
+func (r *UserRepository) UpdateUserCity(userID int64, city string) error {
+	query := `UPDATE users SET user_city = ? WHERE user_id = ?`
+	_, err := r.db.Exec(query, city, userID)
+	if err != nil {
+		return fmt.Errorf("UpdateUserCity қатесі: %w", err)
+	}
+	return nil
+}
+
+// UsersWithoutCity геолокациясы бар, бірақ қаласы әлі анықталмаған қолданушыларды қайтарады (userID -> user_geo).
+func (r *UserRepository) UsersWithoutCity() (map[int64]string, error) {
+	query := `SELECT user_id, user_geo FROM users WHERE user_city = '' AND user_geo IS NOT NULL AND user_geo != ''`
+	return r.queryUserGeo("UsersWithoutCity", query)
+}
+
+// PreciseLocations қолданушы өзі бөліскен (қаладан таңдалмаған) координаталарды қайтарады.
+func (r *UserRepository) PreciseLocations() (map[int64]string, error) {
+	query := `SELECT user_id, user_geo FROM users WHERE geo_approx = 0 AND user_geo IS NOT NULL AND user_geo != ''`
+	return r.queryUserGeo("PreciseLocations", query)
+}
+
+// queryUserGeo user_id мен user_geo бағандарын қайтаратын сұрауды орындайды.
+func (r *UserRepository) queryUserGeo(name, query string) (map[int64]string, error) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s қатесі: %w", name, err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]string)
+	for rows.Next() {
+		var userID int64
+		var geo string
+		if err := rows.Scan(&userID, &geo); err != nil {
+			return nil, fmt.Errorf("%s қатесі: %w", name, err)
+		}
+		result[userID] = geo
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s қатесі: %w", name, err)
+	}
+	return result, nil
+}
+
+// CityCounts геолокациясы бар қолданушыларды қалалар бойынша санайды. Қаласы анықталмағандар бос жолмен есептеледі.
+func (r *UserRepository) CityCounts() (map[string]int, error) {
+	query := `SELECT user_city, COUNT(*) FROM users WHERE user_geo IS NOT NULL AND user_geo != '' GROUP BY user_city`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("CityCounts қатесі: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var city string
+		var count int
+		if err := rows.Scan(&city, &count); err != nil {
+			return nil, fmt.Errorf("CityCounts қатесі: %w", err)
+		}
+		result[city] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("CityCounts қатесі: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateLanguageCode қолданушының тілін жаңартады; өзгермесе, жазба қозғалмайды.
+func (r *UserRepository) UpdateLanguageCode(userID int64, languageCode string) error {
+	query := `UPDATE users SET language_code = ? WHERE user_id = ? AND language_code != ?`
+	_, err := r.db.Exec(query, languageCode, userID, languageCode)
+	if err != nil {
+		return fmt.Errorf("UpdateLanguageCode қатесі: %w", err)
+	}
+	return nil
+}
+
+// UpdateUserBio қолданушының өзі туралы мәтінін жаңартады.
+func (r *UserRepository) UpdateUserBio(userID int64, bio string) error {
+	query := `UPDATE users SET bio = ? WHERE user_id = ?`
+	_, err := r.db.Exec(query, bio, userID)
+	if err != nil {
+		return fmt.Errorf("UpdateUserBio қатесі: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser қолданушының жолын кестеден толық өшіреді.
+func (r *UserRepository) DeleteUser(userID int64) error {
+	query := `DELETE FROM users WHERE user_id = ?`
+	_, err := r.db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("DeleteUser қатесі: %w", err)
+	}
+	return nil
+}
+
+// SetUserActive қолданушыны белсенді не белсенді емес деп белгілейді.
+// Ботты бұғаттаған қолданушы белсенді емес болады және сөйлесушілер тізіміне түспейді.
+func (r *UserRepository) SetUserActive(userID int64, active bool) error {
+	query := `UPDATE users SET is_active = ? WHERE user_id = ?`
+	_, err := r.db.Exec(query, active, userID)
+	if err != nil {
+		return fmt.Errorf("SetUserActive қатесі: %w", err)
+	}
+	return nil
+}
+
+// IsUserActive қолданушының белсенді екенін тексереді. БД-да жоқ қолданушы белсенді деп саналады.
+func (r *UserRepository) IsUserActive(userID int64) (bool, error) {
+	query := `SELECT is_active FROM users WHERE user_id = ?`
+	var active bool
+	err := r.db.QueryRow(query, userID).Scan(&active)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("IsUserActive қатесі: %w", err)
+	}
+	return active, nil
+}
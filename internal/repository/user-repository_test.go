@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"tanysu-bot/traits/database"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestDB открывает пустую SQLite в памяти с актуальной схемой.
+func setupTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Каждое соединение с :memory: — отдельная база.
+	db.SetMaxOpenConns(1)
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUserRepository_SetUserActive(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+
+	assert.NoError(t, repo.InsertUser(&User{UserID: 123}))
+
+	active, err := repo.IsUserActive(123)
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	assert.NoError(t, repo.SetUserActive(123, false))
+	active, err = repo.IsUserActive(123)
+	assert.NoError(t, err)
+	assert.False(t, active)
+
+	// Неизвестный пользователь не считается заблокировавшим бота.
+	active, err = repo.IsUserActive(456)
+	assert.NoError(t, err)
+	assert.True(t, active)
+}
+
+func TestMigrate_AddsMissingColumns(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	// Таблица в том виде, в каком её создавали старые версии бота.
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, ava TEXT, ava_file_id TEXT,
+		user_nickname TEXT, user_name TEXT, user_age INTEGER, user_sex TEXT, user_geo TEXT, first_name TEXT, last_name TEXT, contact TEXT)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO users (user_id) VALUES (123)`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, database.Migrate(db))
+	assert.NoError(t, database.Migrate(db))
+
+	active, err := NewRepository(db).IsUserActive(123)
+	assert.NoError(t, err)
+	assert.True(t, active)
+}
+
+func TestUserRepository_UpdateUserBio(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+
+	assert.NoError(t, repo.InsertUser(&User{UserID: 123}))
+	user, err := repo.GetUser(123)
+	assert.NoError(t, err)
+	assert.Equal(t, "", user.Bio)
+
+	assert.NoError(t, repo.UpdateUserBio(123, "Кітап оқығанды ұнатамын"))
+	user, err = repo.GetUser(123)
+	assert.NoError(t, err)
+	assert.Equal(t, "Кітап оқығанды ұнатамын", user.Bio)
+}
+
+func TestUserRepository_DeleteUser(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+
+	assert.NoError(t, repo.InsertUser(&User{UserID: 123}))
+	assert.NoError(t, repo.InsertUser(&User{UserID: 456}))
+	assert.NoError(t, repo.DeleteUser(123))
+
+	exists, err := repo.UserExists(123)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = repo.UserExists(456)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestUserRepository_UserCity(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+
+	assert.NoError(t, repo.InsertUser(&User{UserID: 123, UserGeo: "43.23890,76.88970"}))
+	assert.NoError(t, repo.InsertUser(&User{UserID: 456}))
+
+	missing, err := repo.UsersWithoutCity()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]string{123: "43.23890,76.88970"}, missing)
+
+	assert.NoError(t, repo.UpdateUserCity(123, "Алматы"))
+	user, err := repo.GetUser(123)
+	assert.NoError(t, err)
+	assert.Equal(t, "Алматы", user.UserCity)
+
+	missing, err = repo.UsersWithoutCity()
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestUserRepository_UpdateUserLocation(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+
+	assert.NoError(t, repo.InsertUser(&User{UserID: 123}))
+	assert.NoError(t, repo.UpdateUserLocation(123, "43.23890,76.88970", "Алматы", true))
+
+	user, err := repo.GetUser(123)
+	assert.NoError(t, err)
+	assert.Equal(t, "43.23890,76.88970", user.UserGeo)
+	assert.Equal(t, "Алматы", user.UserCity)
+	assert.True(t, user.GeoApprox)
+
+	assert.NoError(t, repo.UpdateUserLocation(123, "43.25667,76.92861", "Алматы", false))
+	user, err = repo.GetUser(123)
+	assert.NoError(t, err)
+	assert.False(t, user.GeoApprox)
+}
+
+func TestUserRepository_UpdateLanguageCode(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+
+	assert.NoError(t, repo.InsertUser(&User{UserID: 123, LanguageCode: "kk"}))
+	user, err := repo.GetUser(123)
+	assert.NoError(t, err)
+	assert.Equal(t, "kk", user.LanguageCode)
+
+	assert.NoError(t, repo.UpdateLanguageCode(123, "ru"))
+	user, err = repo.GetUser(123)
+	assert.NoError(t, err)
+	assert.Equal(t, "ru", user.LanguageCode)
+}
+
+func TestUserRepository_CityCounts(t *testing.T) {
+	repo := NewRepository(setupTestDB(t))
+
+	assert.NoError(t, repo.InsertUser(&User{UserID: 1, UserGeo: "43.23890,76.88970", UserCity: "Алматы"}))
+	assert.NoError(t, repo.InsertUser(&User{UserID: 2, UserGeo: "43.26000,76.92000", UserCity: "Алматы"}))
+	assert.NoError(t, repo.InsertUser(&User{UserID: 3, UserGeo: "10.00000,10.00000"}))
+	assert.NoError(t, repo.InsertUser(&User{UserID: 4}))
+
+	counts, err := repo.CityCounts()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Алматы": 2, "": 1}, counts)
+}
@@ -0,0 +1,247 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// ErrQueueFull возвращается, если очередь отправки в чат переполнена.
+var ErrQueueFull = errors.New("send queue is full")
+
+// pruneLimitersAfter — с какого числа запомненных чатов чистить лимиты простаивающих.
+const pruneLimitersAfter = 1024
+
+// Request — одна отправка в Telegram, например вызов b.SendMessage.
+// При ошибке 429 Request повторяется целиком, поэтому он должен делать ровно один вызов API.
+type Request func(ctx context.Context) (*models.Message, error)
+
+// Limits — ограничения частоты отправки.
+type Limits struct {
+	GlobalPerSecond float64 // на весь бот
+	ChatPerSecond   float64 // в личный чат
+	GroupPerMinute  float64 // в группу или канал
+	Burst           int     // сколько отправок в чат подряд разрешено без ожидания
+	QueueSize       int     // максимальная длина очереди одного чата
+	MaxRetries      int     // сколько раз повторять отправку после 429
+}
+
+// Sender отправляет сообщения через очереди чатов: в один чат — по порядку и не чаще лимита,
+// в разные чаты — параллельно, но не чаще общего лимита бота.
+type Sender struct {
+	limits Limits
+
+	mu       sync.Mutex
+	global   *bucket
+	limiters map[string]*bucket
+	queues   map[string][]job
+	now      func() time.Time
+}
+
+type job struct {
+	ctx  context.Context
+	req  Request
+	done func(*models.Message, error)
+}
+
+func New(limits Limits) *Sender {
+	limits.QueueSize = max(limits.QueueSize, 1)
+	return &Sender{
+		limits:   limits,
+		global:   newBucket(limits.GlobalPerSecond, limits.GlobalPerSecond),
+		limiters: make(map[string]*bucket),
+		queues:   make(map[string][]job),
+		now:      time.Now,
+	}
+}
+
+// Send ставит отправку в очередь чата и ждёт результата.
+func (s *Sender) Send(ctx context.Context, chatID any, req Request) (*models.Message, error) {
+	type result struct {
+		msg *models.Message
+		err error
+	}
+	results := make(chan result, 1)
+	if err := s.enqueue(chatID, job{ctx: ctx, req: req, done: func(msg *models.Message, err error) {
+		results <- result{msg, err}
+	}}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case r := <-results:
+		return r.msg, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Go ставит отправку в очередь и сразу возвращается. done (может быть nil) вызывается
+// с результатом; если done не задан, ошибка только выводится в лог.
+func (s *Sender) Go(chatID any, req Request, done func(*models.Message, error)) error {
+	if done == nil {
+		done = func(_ *models.Message, err error) {
+			if err != nil {
+				fmt.Printf("Ошибка при отправке в чат %v: %v\n", chatID, err)
+			}
+		}
+	}
+	return s.enqueue(chatID, job{ctx: context.Background(), req: req, done: done})
+}
+
+// QueueLen возвращает длину очереди чата.
+func (s *Sender) QueueLen(chatID any) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.queues[chatKey(chatID)])
+}
+
+func (s *Sender) enqueue(chatID any, j job) error {
+	key := chatKey(chatID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, running := s.queues[key]
+	if len(queue) >= s.limits.QueueSize {
+		return ErrQueueFull
+	}
+	s.queues[key] = append(queue, j)
+	if !running {
+		if _, ok := s.limiters[key]; !ok {
+			if len(s.limiters) >= pruneLimitersAfter {
+				s.pruneLimiters()
+			}
+			s.limiters[key] = s.chatBucket(chatID)
+		}
+		go s.drain(key)
+	}
+	return nil
+}
+
+// drain выполняет отправки чата по порядку, пока очередь не опустеет.
+func (s *Sender) drain(key string) {
+	for {
+		s.mu.Lock()
+		j := s.queues[key][0]
+		s.mu.Unlock()
+
+		msg, err := s.do(key, j)
+		j.done(msg, err)
+
+		s.mu.Lock()
+		queue := s.queues[key][1:]
+		if len(queue) == 0 {
+			delete(s.queues, key)
+			s.mu.Unlock()
+			return
+		}
+		s.queues[key] = queue
+		s.mu.Unlock()
+	}
+}
+
+// do выполняет отправку с учётом лимитов и повторяет её после 429, выждав retry_after.
+func (s *Sender) do(key string, j job) (*models.Message, error) {
+	for attempt := 0; ; attempt++ {
+		s.mu.Lock()
+		now := s.now()
+		wait := max(s.limiters[key].reserve(now), s.global.reserve(now))
+		s.mu.Unlock()
+
+		if err := sleep(j.ctx, wait); err != nil {
+			return nil, err
+		}
+
+		msg, err := j.req(j.ctx)
+		var tooMany *bot.TooManyRequestsError
+		if !errors.As(err, &tooMany) || attempt >= s.limits.MaxRetries {
+			return msg, err
+		}
+		fmt.Printf("[SENDER] 429 для чата %s, повтор через %d с\n", key, tooMany.RetryAfter)
+		if err := sleep(j.ctx, time.Duration(tooMany.RetryAfter)*time.Second); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// chatBucket подбирает лимит по типу чата: у групп и каналов (отрицательный ID или @username) он строже.
+func (s *Sender) chatBucket(chatID any) *bucket {
+	if id, ok := chatID.(int64); ok && id > 0 {
+		return newBucket(s.limits.ChatPerSecond, float64(s.limits.Burst))
+	}
+	return newBucket(s.limits.GroupPerMinute/60, float64(s.limits.Burst))
+}
+
+// pruneLimiters забывает лимиты чатов без очереди, которые уже полностью восстановились.
+// Вызывается под мьютексом.
+func (s *Sender) pruneLimiters() {
+	now := s.now()
+	for key, limiter := range s.limiters {
+		if _, busy := s.queues[key]; !busy && limiter.full(now) {
+			delete(s.limiters, key)
+		}
+	}
+}
+
+func chatKey(chatID any) string {
+	return fmt.Sprint(chatID)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bucket — token bucket: rate токенов в секунду, не больше burst в запасе.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate, burst float64) *bucket {
+	return &bucket{rate: rate, burst: max(burst, 1), tokens: max(burst, 1)}
+}
+
+// reserve забирает токен и возвращает, сколько нужно подождать перед отправкой.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.refill(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+func (b *bucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
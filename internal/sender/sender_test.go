@@ -0,0 +1,113 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLimits() Limits {
+	return Limits{QueueSize: 10, MaxRetries: 2}
+}
+
+func TestSender_SendReturnsResult(t *testing.T) {
+	s := New(testLimits())
+
+	msg, err := s.Send(context.Background(), int64(1), func(ctx context.Context) (*models.Message, error) {
+		return &models.Message{ID: 42}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, msg.ID)
+	assert.Equal(t, 0, s.QueueLen(int64(1)))
+}
+
+func TestSender_RetryAfter429(t *testing.T) {
+	s := New(testLimits())
+
+	attempts := 0
+	msg, err := s.Send(context.Background(), int64(1), func(ctx context.Context) (*models.Message, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, &bot.TooManyRequestsError{Message: "Too Many Requests", RetryAfter: 0}
+		}
+		return &models.Message{ID: 1}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, msg.ID)
+	assert.Equal(t, 3, attempts)
+
+	// После MaxRetries повторов ошибка возвращается вызывающему.
+	attempts = 0
+	_, err = s.Send(context.Background(), int64(1), func(ctx context.Context) (*models.Message, error) {
+		attempts++
+		return nil, &bot.TooManyRequestsError{Message: "Too Many Requests", RetryAfter: 0}
+	})
+	assert.True(t, bot.IsTooManyRequestsError(err))
+	assert.Equal(t, 3, attempts)
+
+	// Другие ошибки не повторяются.
+	attempts = 0
+	_, err = s.Send(context.Background(), int64(1), func(ctx context.Context) (*models.Message, error) {
+		attempts++
+		return nil, bot.ErrorForbidden
+	})
+	assert.True(t, errors.Is(err, bot.ErrorForbidden))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestSender_OrderAndQueueLimit(t *testing.T) {
+	limits := testLimits()
+	limits.QueueSize = 3
+	s := New(limits)
+
+	release := make(chan struct{})
+	var (
+		mu   sync.Mutex
+		seen []int
+		wg   sync.WaitGroup
+	)
+	request := func(id int) Request {
+		return func(ctx context.Context) (*models.Message, error) {
+			<-release
+			return &models.Message{ID: id}, nil
+		}
+	}
+	done := func(msg *models.Message, err error) {
+		mu.Lock()
+		seen = append(seen, msg.ID)
+		mu.Unlock()
+		wg.Done()
+	}
+
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		assert.NoError(t, s.Go("@channel", request(i), done))
+	}
+	assert.ErrorIs(t, s.Go("@channel", request(4), done), ErrQueueFull)
+	assert.Equal(t, 3, s.QueueLen("@channel"))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{1, 2, 3}, seen)
+}
+
+func TestBucket_Reserve(t *testing.T) {
+	now := time.Now()
+	b := newBucket(1, 2)
+
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, time.Second, b.reserve(now))
+	assert.Equal(t, 2*time.Second, b.reserve(now))
+
+	// Через 10 секунд запас восстановлен, но не больше burst.
+	later := now.Add(10 * time.Second)
+	assert.True(t, b.full(later))
+	assert.Equal(t, time.Duration(0), b.reserve(later))
+}
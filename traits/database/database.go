@@ -33,6 +33,16 @@ func DatabaseConnection(cfg *config.Config) *sql.DB {
 
 	log.Println("Успешное подключение к SQLite!")
 
+	if err := Migrate(db); err != nil {
+		log.Fatalf("Ошибка при создании таблицы users: %v", err)
+	}
+	log.Println("Таблица users успешно создана (если не существовала).")
+
+	return db
+}
+
+// Migrate создаёт таблицу users и добавляет колонки, появившиеся позже.
+func Migrate(db *sql.DB) error {
 	// Скрипт для создания таблицы, если она не существует.
 	createTableQuery := `
 	CREATE TABLE IF NOT EXISTS users (
@@ -47,15 +57,46 @@ func DatabaseConnection(cfg *config.Config) *sql.DB {
 		user_geo TEXT,
 		first_name TEXT,
 		last_name TEXT,
-		contact TEXT
+		contact TEXT,
+		is_active INTEGER NOT NULL DEFAULT 1,
+		bio TEXT NOT NULL DEFAULT '',
+		user_city TEXT NOT NULL DEFAULT '',
+		geo_approx INTEGER NOT NULL DEFAULT 0,
+		language_code TEXT NOT NULL DEFAULT ''
 	);
 	`
 
 	// Выполняем запрос на создание таблицы.
 	if _, err := db.Exec(createTableQuery); err != nil {
-		log.Fatalf("Ошибка при создании таблицы users: %v", err)
+		return err
 	}
-	log.Println("Таблица users успешно создана (если не существовала).")
 
-	return db
+	// В базах, созданных до появления колонок, CREATE TABLE IF NOT EXISTS их не добавит.
+	if err := ensureColumn(db, "users", "is_active", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "users", "bio", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "users", "user_city", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "users", "geo_approx", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	return ensureColumn(db, "users", "language_code", "TEXT NOT NULL DEFAULT ''")
+}
+
+// ensureColumn добавляет колонку в таблицу, если её там ещё нет.
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	var count int
+	query := `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
+	if err := db.QueryRow(query, table, column).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
 }